@@ -0,0 +1,124 @@
+package check
+
+import (
+    "fmt"
+    "pptxt/dict"
+    "pptxt/fileio"
+    "pptxt/reporter"
+    "time"
+)
+
+// the book being checked and everything the checks share
+type Book struct {
+    Wb []string  // working buffer, one text line per element
+    Pb []string  // paragraph buffer, one paragraph per element
+    Pl []int  // line in Wb where each paragraph in Pb starts
+    Wd []dict.Dictionary  // working dictionaries inc. goodwords
+    Jd map[string]int  // jeebies phrase counts
+    Sd map[string]string  // scannos and corrections
+    Vd [][]string  // spelling variant sets
+
+    LangSpans   bool  // check <lang xx> spans against that language
+    Long        int  // report lines longer than this
+    Short       int  // report lines within a paragraph shorter than this
+    AllowMarkup []string  // markup classes not to report
+
+    Suspects []string  // suspect words, set by spellcheck
+    Good     []string  // good words in the text, set by spellcheck
+    spelled  bool  // spellcheck has run
+
+    Logs   bool  // save each check's results to its own log file
+    Runlog *[]string  // the run log, logpptxt.txt
+    Report *reporter.Report
+
+    logs  map[string][]string  // logs shared by several checks
+    order []string  // the shared logs in the order they were started
+}
+
+// a check of the book. Run adds its section to the report and returns
+// its findings
+type Check interface {
+    Name() string  // i.e. "spellcheck", used with -checks and -skip
+    Description() string
+    Run(b *Book) []reporter.Finding
+}
+
+// the log file for a check, "" if per-check logs are off
+func (b *Book) Logname(fname string) string {
+    if !b.Logs {
+        return ""
+    }
+    return fname
+}
+
+// add lines to a log shared by several checks, started with a header
+// for title. shared logs are saved by Save
+func (b *Book) Log(fname string, title string, lines ...string) {
+    if b.logs == nil {
+        b.logs = make(map[string][]string)
+    }
+    if _, ok := b.logs[fname]; !ok {
+        b.logs[fname] = []string{fmt.Sprintf("%s report \nstarted: %s\n------------------------------",
+            title, time.Now().Format(time.RFC850))}
+        b.order = append(b.order, fname)
+    }
+    b.logs[fname] = append(b.logs[fname], lines...)
+}
+
+// save the shared logs, unless per-check logs are off
+func (b *Book) Save() {
+    if !b.Logs {
+        return
+    }
+    for _, fname := range b.order {
+        fileio.SaveText(b.logs[fname], fname, true, true)
+    }
+}
+
+// run the checks in order, adding their findings to the report
+func Run(b *Book, checks []Check) {
+    for _, c := range checks {
+        b.Report.Find(c.Run(b)...)
+    }
+    b.Save()
+}
+
+// add a check to the registry. it runs after those already there
+func Register(c Check) {
+    registry = append(registry, c)
+}
+
+// every registered check, in the order they run
+func All() []Check {
+    return registry
+}
+
+// the registered checks named in only, or all of them if only is empty,
+// less those named in skip
+func Select(only []string, skip []string) ([]Check, error) {
+    known := make(map[string]bool)
+    for _, c := range registry {
+        known[c.Name()] = true
+    }
+    want := make(map[string]bool)
+    for _, name := range only {
+        if !known[name] {
+            return nil, fmt.Errorf("unknown check %s in -checks", name)
+        }
+        want[name] = true
+    }
+    drop := make(map[string]bool)
+    for _, name := range skip {
+        if !known[name] {
+            return nil, fmt.Errorf("unknown check %s in -skip", name)
+        }
+        drop[name] = true
+    }
+    var checks []Check
+    for _, c := range registry {
+        if (len(want) == 0 || want[c.Name()]) && !drop[c.Name()] {
+            checks = append(checks, c)
+        }
+    }
+    return checks, nil
+}
@@ -0,0 +1,119 @@
+package check
+
+import (
+    "fmt"
+    "pptxt/hyphen"
+    "pptxt/jeebies"
+    "pptxt/leven"
+    "pptxt/reporter"
+    "pptxt/scanno"
+    "pptxt/spellcheck"
+    "pptxt/textcheck"
+    "pptxt/variant"
+)
+
+// the checks in the order they run. to add a check, implement Check
+// and add it here, or Register it
+var registry = builtin()
+
+func builtin() []Check {
+    checks := []Check{
+        spellCheck{},
+        levenCheck{},  // after spellcheck, for its suspect words
+        jeebiesCheck{},
+        scannoCheck{},
+        hyphenCheck{},
+        variantCheck{},
+    }
+    for _, t := range textcheck.Tests {
+        checks = append(checks, textCheck{t})
+    }
+    return checks
+}
+
+type spellCheck struct{}
+
+func (spellCheck) Name() string { return "spellcheck" }
+
+func (spellCheck) Description() string { return "words not in the dictionaries" }
+
+func (spellCheck) Run(b *Book) []reporter.Finding {
+    var fs []reporter.Finding
+    b.Suspects, b.Good, fs = spellcheck.Spellcheck(b.Wb, b.Wd, b.LangSpans, b.Runlog, b.Report, b.Logname("logspell.txt"))
+    b.spelled = true
+    return fs
+}
+
+type levenCheck struct{}
+
+func (levenCheck) Name() string { return "levenshtein" }
+
+func (levenCheck) Description() string { return "suspect words one letter from a good word in the text" }
+
+func (levenCheck) Run(b *Book) []reporter.Finding {
+    if !b.spelled {  // spellcheck skipped: find the suspect words without reporting them
+        var rs []string
+        b.Suspects, b.Good, _ = spellcheck.Spellcheck(b.Wb, b.Wd, b.LangSpans, &rs, &reporter.Report{}, "")
+        b.spelled = true
+    }
+    return leven.Levencheck(b.Wb, b.Good, b.Suspects, b.Runlog, b.Report, b.Logname("loglev.txt"))
+}
+
+type jeebiesCheck struct{}
+
+func (jeebiesCheck) Name() string { return "jeebies" }
+
+func (jeebiesCheck) Description() string { return "he/be, hut/but and had/bad confusion" }
+
+func (jeebiesCheck) Run(b *Book) []reporter.Finding {
+    return jeebies.Jeebies(b.Pb, b.Pl, b.Wb, b.Jd, b.Runlog, b.Report, b.Logname("logjeebies.txt"))
+}
+
+type scannoCheck struct{}
+
+func (scannoCheck) Name() string { return "scanno" }
+
+func (scannoCheck) Description() string { return "known scannos, with their corrections" }
+
+func (scannoCheck) Run(b *Book) []reporter.Finding {
+    return scanno.Scannocheck(b.Wb, b.Sd, b.Runlog, b.Report, b.Logname("logscanno.txt"))
+}
+
+type hyphenCheck struct{}
+
+func (hyphenCheck) Name() string { return "hyphenation" }
+
+func (hyphenCheck) Description() string { return "compounds both hyphenated and joined or spaced" }
+
+func (hyphenCheck) Run(b *Book) []reporter.Finding {
+    return hyphen.Hyphencheck(b.Pb, b.Pl, b.Wb, b.Runlog, b.Report, b.Logname("loghyphen.txt"))
+}
+
+type variantCheck struct{}
+
+func (variantCheck) Name() string { return "variants" }
+
+func (variantCheck) Description() string { return "more than one spelling of a word, i.e. color and colour" }
+
+func (variantCheck) Run(b *Book) []reporter.Finding {
+    return variant.Variantcheck(b.Wb, b.Vd, b.Runlog, b.Report, b.Logname("logvariant.txt"))
+}
+
+// one of the text checks. they share the log logtext.txt
+type textCheck struct {
+    t textcheck.Test
+}
+
+func (c textCheck) Name() string { return c.t.ID }
+
+func (c textCheck) Description() string { return c.t.Description }
+
+func (c textCheck) Run(b *Book) []reporter.Finding {
+    r := c.t.Run(textcheck.Text{Pb: b.Pb, Pl: b.Pl, Wb: b.Wb,
+        Long: b.Long, Short: b.Short, AllowMarkup: b.AllowMarkup})
+    b.Report.AddSection(reporter.Section{Name: r.Name, Count: r.Count, Lines: r.Lines,
+        Marks: r.Marks, LineMarks: r.LineMarks})
+    b.Log("logtext.txt", "text check", append([]string{r.Name}, r.Lines...)...)
+    *b.Runlog = append(*b.Runlog, fmt.Sprintf("text check %s: %d", c.t.ID, r.Count))
+    return r.Findings
+}
@@ -4,40 +4,183 @@ import (
     "os"
     "log"
     "bufio"
+    "fmt"
+    "sort"
     "strings"
+    "strconv"
 )
 
 var BOM = string([]byte{239, 187, 191}) // UTF-8 specific
 
-// dictionary word list in in pptxt.dat bracketed by
-// *** BEGIN DICT *** and *** END DICT ***
-func ReadDict(infile string) []string {
+// pptxt.dat holds named sections, each bracketed by
+// *** BEGIN name *** and *** END name ***
+// i.e. the English word list is in the DICT section,
+// other languages in DICT:fr, DICT:de and so on
+type Sections map[string][]string
+
+// section name if line is a BEGIN or END marker, else ""
+func marker(line string, kind string) string {
+    prefix := "*** " + kind + " "
+    if strings.HasPrefix(line, prefix) && strings.HasSuffix(line, " ***") &&
+        len(line) > len(prefix)+4 {
+        return strings.TrimSpace(line[len(prefix) : len(line)-4])
+    }
+    return ""
+}
+
+// reads every section in a pptxt.dat file
+// sections must be closed, not nested and not repeated
+// lines outside of any section are ignored
+func ReadSections(infile string) (Sections, error) {
     file, err := os.Open(infile)
-    if err != nil { log.Fatal(err) }
+    if err != nil {
+        return nil, err
+    }
     defer file.Close()
-    wd := []string{}
+    ds := make(Sections)
     scanner := bufio.NewScanner(file)
-    keep := false
+    current := ""  // name of the section being read
+    n := 0
     for scanner.Scan() {
-        if scanner.Text() == "*** BEGIN DICT ***" {
-            keep = true
-            continue    
+        n++
+        line := scanner.Text()
+        if n == 1 {
+            line = strings.TrimPrefix(line, BOM)  // remove BOM if present
+        }
+        if name := marker(line, "BEGIN"); name != "" {
+            if current != "" {
+                return nil, fmt.Errorf("%s:%d: BEGIN %s inside unclosed section %s",
+                    infile, n, name, current)
+            }
+            if _, ok := ds[name]; ok {
+                return nil, fmt.Errorf("%s:%d: duplicate section %s", infile, n, name)
+            }
+            current = name
+            ds[name] = []string{}
+            continue
         }
-        if scanner.Text() == "*** END DICT ***" {
-            keep = false
+        if name := marker(line, "END"); name != "" {
+            if current == "" {
+                return nil, fmt.Errorf("%s:%d: END %s without BEGIN %s",
+                    infile, n, name, name)
+            }
+            if name != current {
+                return nil, fmt.Errorf("%s:%d: END %s inside unclosed section %s",
+                    infile, n, name, current)
+            }
+            current = ""
             continue
         }
-        if keep {
-            wd = append(wd, scanner.Text())
+        if current != "" && line != "" {
+            ds[current] = append(ds[current], line)
         }
     }
-    if err := scanner.Err(); err != nil { log.Fatal(err) }
+    if err := scanner.Err(); err != nil {
+        return nil, err
+    }
+    if current != "" {
+        return nil, fmt.Errorf("%s: section %s is not closed", infile, current)
+    }
+    return ds, nil
+}
 
-    // remove BOM if present
-    wd[0] = strings.TrimPrefix(wd[0], BOM)
+// the lines of the named section
+func (ds Sections) Block(name string) ([]string, error) {
+    lines, ok := ds[name]
+    if !ok {
+        return nil, fmt.Errorf("no %s section", name)
+    }
+    return lines, nil
+}
+
+// dictionary word list from the DICT section, one word per line
+// a copy, so goodwords can be added without changing the section
+func Words(lines []string) []string {
+    wd := []string{}
+    for _, line := range lines {
+        wd = append(wd, line)
+    }
     return wd
 }
 
+// Lang of the project-specific goodwords.txt word list
+const GoodWords = "goodwords"
+
+// a word list for one language, sorted for binary search
+type Dictionary struct {
+    Lang  string  // i.e. "en", "fr"
+    Words []string
+}
+
+func NewDictionary(lang string, words []string) Dictionary {
+    sort.Strings(words)
+    return Dictionary{lang, words}
+}
+
+func (d Dictionary) Contains(word string) bool {
+    ip := sort.SearchStrings(d.Words, word) // where it would insert
+    return (ip != len(d.Words) && d.Words[ip] == word) // true if we found it
+}
+
+// the dictionary for a language from its DICT:lang section
+// English may also be in the plain DICT section
+func (ds Sections) Dict(lang string) (Dictionary, error) {
+    lines, err := ds.Block("DICT:" + lang)
+    if err != nil && lang == "en" {
+        lines, err = ds.Block("DICT")
+    }
+    if err != nil {
+        return Dictionary{}, err
+    }
+    return NewDictionary(lang, Words(lines)), nil
+}
+
+// jeebies context statistics from the JEEBIES section
+// one phrase per line with its count, i.e. "and he said:310"
+func Jeebies(lines []string) map[string]int {
+    jd := make(map[string]int)
+    for _, line := range lines {
+        t := strings.Split(line, ":")
+        if len(t) != 2 {
+            continue  // not a phrase:count line
+        }
+        count, err := strconv.Atoi(t[1])
+        if err != nil {
+            continue
+        }
+        jd[t[0]] = count
+    }
+    return jd
+}
+
+// scannos from the SCANNOS section
+// one scanno per line with its correction, i.e. "tbe:the"
+func Scannos(lines []string) map[string]string {
+    sd := make(map[string]string)
+    for _, line := range lines {
+        t := strings.Split(line, ":")
+        if len(t) != 2 {
+            continue  // not a scanno:correction line
+        }
+        sd[t[0]] = t[1]
+    }
+    return sd
+}
+
+// spelling variant sets from the VARIANTS section
+// one set per line, words separated by spaces, i.e. "color colour"
+func Variants(lines []string) [][]string {
+    vd := [][]string{}
+    for _, line := range lines {
+        t := strings.Fields(line)
+        if len(t) < 2 {
+            continue  // not a set of variants
+        }
+        vd = append(vd, t)
+    }
+    return vd
+}
+
 func ReadWordList(infile string) []string {
     wd := []string{}
     file, err := os.Open(infile)  // try to open wordlist
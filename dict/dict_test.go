@@ -0,0 +1,70 @@
+package dict
+
+import (
+    "os"
+    "path/filepath"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+// write content to a data file in a temporary directory
+func datfile(t *testing.T, content string) string {
+    t.Helper()
+    fname := filepath.Join(t.TempDir(), "pptxt.dat")
+    if err := os.WriteFile(fname, []byte(content), 0644); err != nil {
+        t.Fatal(err)
+    }
+    return fname
+}
+
+func TestReadSections(t *testing.T) {
+    fname := datfile(t, BOM+`header, outside any section
+*** BEGIN DICT ***
+apple
+banana
+
+*** END DICT ***
+*** BEGIN SCANNOS ***
+tbe:the
+*** END SCANNOS ***
+`)
+    ds, err := ReadSections(fname)
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := Sections{
+        "DICT":    {"apple", "banana"},
+        "SCANNOS": {"tbe:the"},
+    }
+    if !reflect.DeepEqual(ds, want) {
+        t.Errorf("got %v, want %v", ds, want)
+    }
+}
+
+func TestReadSectionsErrors(t *testing.T) {
+    tests := []struct {
+        name    string
+        content string
+        err     string  // expected in the error message
+    }{
+        {"unclosed", "*** BEGIN DICT ***\napple\n", "section DICT is not closed"},
+        {"nested", "*** BEGIN DICT ***\n*** BEGIN SCANNOS ***\n*** END SCANNOS ***\n*** END DICT ***\n",
+            ":2: BEGIN SCANNOS inside unclosed section DICT"},
+        {"duplicate", "*** BEGIN DICT ***\n*** END DICT ***\n*** BEGIN DICT ***\n*** END DICT ***\n",
+            ":3: duplicate section DICT"},
+        {"end without begin", "apple\n*** END DICT ***\n", ":2: END DICT without BEGIN DICT"},
+        {"mismatched end", "*** BEGIN DICT ***\n*** END SCANNOS ***\n",
+            ":2: END SCANNOS inside unclosed section DICT"},
+    }
+    for _, tt := range tests {
+        _, err := ReadSections(datfile(t, tt.content))
+        if err == nil {
+            t.Errorf("%s: no error", tt.name)
+            continue
+        }
+        if !strings.Contains(err.Error(), tt.err) {
+            t.Errorf("%s: error %q, want %q", tt.name, err, tt.err)
+        }
+    }
+}
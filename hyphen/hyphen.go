@@ -0,0 +1,177 @@
+package hyphen
+
+import (
+    "fmt"
+    "pptxt/fileio"
+    "pptxt/para"
+    "pptxt/reporter"
+    "pptxt/wfreq"
+    "regexp"
+    "sort"
+    "strings"
+    "time"
+)
+
+// a word in a paragraph, lower case, with its byte offsets
+// spaced is true if only spaces separate it from the word before
+type token struct {
+    word   string
+    start  int
+    end    int
+    spaced bool
+}
+
+// lines in wb with a word matching word, ignoring case
+// the words found are added to marks
+func wordLines(wb2 []map[string]struct{}, word string, marks map[int][]string) []int {
+    var lines []int
+    for n, words := range wb2 {
+        for w := range words {
+            if strings.ToLower(w) == word {
+                lines = append(lines, n)
+                marks[n] = append(marks[n], w)
+                break
+            }
+        }
+    }
+    return lines
+}
+
+// lines in wb where the parts appear as separate words with only
+// spaces, or a line break, between them
+// the words found are added to marks
+func spacedLines(pb []string, pt [][]token, pl []int, wb []string, at map[string][][2]int, parts []string, marks map[int][]string) []int {
+    var lines []int
+    for _, loc := range at[parts[0]] {
+        tk := pt[loc[0]]
+        i := loc[1]
+        if i+len(parts) > len(tk) {
+            continue
+        }
+        match := true
+        for j, part := range parts[1:] {
+            if tk[i+j+1].word != part || !tk[i+j+1].spaced {
+                match = false
+                break
+            }
+        }
+        if match {
+            n := para.Line(wb, pl[loc[0]], tk[i].start)
+            lines = append(lines, n)
+            p := pb[loc[0]]
+            if phrase := p[tk[i].start:tk[i+len(parts)-1].end]; strings.Contains(wb[n], phrase) {
+                marks[n] = append(marks[n], phrase)
+            } else {  // crosses a line break
+                marks[n] = append(marks[n], p[tk[i].start:tk[i].end])
+            }
+        }
+    }
+    return lines
+}
+
+// hyphenation check reports compounds that appear in more than one of
+// hyphenated, joined and spaced forms, i.e. to-day, today and to day
+// returns a finding for each line with one of those forms
+func Hyphencheck(pb []string, pl []int, wb []string, runlog *[]string, rpt *reporter.Report, fname string) []reporter.Finding {
+    var s []string
+    var rs []string
+    rs = append(rs, "hyphenation check")
+
+    s = append(s, fmt.Sprintf("hyphenation check report \nstarted: %s\n------------------------------",
+        time.Now().Format(time.RFC850)))
+
+    wlm, wb2 := wfreq.GetWordList(wb) // wordlist with word, frequency of word in map, wb2
+    lwm := make(map[string]int)  // the same, case insensitive
+    for word, count := range wlm {
+        lwm[strings.ToLower(word)] += count
+    }
+
+    // the words in each paragraph, for the spaced forms which may cross lines
+    var re = regexp.MustCompile(`[\p{L}\p{N}'’]+`)
+    pt := make([][]token, len(pb))
+    at := make(map[string][][2]int)  // paragraph and token index of each word
+    for n, p := range pb {
+        end := 0
+        for _, loc := range re.FindAllStringIndex(p, -1) {
+            w := strings.ToLower(strings.Replace(p[loc[0]:loc[1]], "’", "'", -1))
+            spaced := end > 0 && strings.TrimSpace(p[end:loc[0]]) == ""
+            at[w] = append(at[w], [2]int{n, len(pt[n])})
+            pt[n] = append(pt[n], token{w, loc[0], loc[1], spaced})
+            end = loc[1]
+        }
+    }
+
+    var hwords []string
+    for word := range lwm {
+        if strings.Contains(word, "-") {
+            hwords = append(hwords, word)
+        }
+    }
+    sort.Strings(hwords)
+
+    count := 0
+    marks := make(map[int][]string)  // forms of the compounds on each line
+    var fs []reporter.Finding
+    for _, hword := range hwords {
+        parts := strings.Split(hword, "-")
+        joined := strings.Join(parts, "")
+        spaced := strings.Join(parts, " ")
+        found := make(map[int][]string)  // added to marks if reported
+        jlines := []int{}
+        if lwm[joined] > 0 {
+            jlines = wordLines(wb2, joined, found)
+        }
+        slines := spacedLines(pb, pt, pl, wb, at, parts, found)
+        if len(jlines) == 0 && len(slines) == 0 {
+            continue  // used consistently
+        }
+        count++
+        hlines := wordLines(wb2, hword, found)
+        for n, words := range found {
+            marks[n] = append(marks[n], words...)
+        }
+        summary := fmt.Sprintf("%s (%d) / %s (%d) / %s (%d)",
+            hword, lwm[hword], joined, lwm[joined], spaced, len(slines))
+        s = append(s, summary)
+        for _, v := range []struct {
+            form  string
+            lines []int
+        }{{hword, hlines}, {joined, jlines}, {spaced, slines}} {
+            if len(v.lines) == 0 {
+                continue
+            }
+            s = append(s, fmt.Sprintf("  %s", v.form))
+            for _, n := range v.lines {
+                s = append(s, fmt.Sprintf("  %6d: %s", n+1, wb[n]))
+                word := v.form
+                for _, w := range found[n] {
+                    if strings.ToLower(w) == v.form {
+                        word = w  // as written in the text
+                    }
+                }
+                fs = append(fs, reporter.Finding{Check: "hyphenation", Severity: reporter.Info,
+                    Line: n + 1, Column: reporter.Column(strings.ToLower(wb[n]), strings.ToLower(word)), Word: word,
+                    Message: "inconsistent hyphenation: " + summary})
+            }
+        }
+        s = append(s, "")
+    }
+    if count == 0 {
+        s = append(s, "  no inconsistent hyphenation found in text.")
+    }
+
+    rs = append(rs, fmt.Sprintf("  hyphenated words in text: %d", len(hwords)))
+    rs = append(rs, fmt.Sprintf("  inconsistently hyphenated: %d", count))
+
+    // generate loghyphen.txt from s, unless per-check logs are off
+    if fname != "" {
+        fileio.SaveText(s, fname, true, true)
+    }
+
+    // add to the report, without the log header
+    rpt.Add("hyphenation check", count, s[1:], marks)
+
+    // append to pptxt.log
+    *runlog = append(*runlog, rs...)
+    return fs
+}
@@ -0,0 +1,148 @@
+package jeebies
+
+import (
+    "fmt"
+    "pptxt/fileio"
+    "pptxt/para"
+    "pptxt/reporter"
+    "regexp"
+    "strings"
+    "time"
+)
+
+// word pairs commonly confused by OCR
+var pairs = [][2]string{
+    {"he", "be"},
+    {"hut", "but"},
+    {"had", "bad"},
+}
+
+// a word in a paragraph and its byte offsets
+type token struct {
+    word  string  // lower case
+    start int
+    end   int
+}
+
+// score of word w in its context using the jeebies statistics in jd
+// a three-word phrase match counts for more than the two-word phrases
+func score(jd map[string]int, prev string, w string, next string) int {
+    sc := 0
+    if prev != "" && next != "" {
+        sc += 4 * jd[prev+" "+w+" "+next]
+    }
+    if prev != "" {
+        sc += jd[prev+" "+w]
+    }
+    if next != "" {
+        sc += jd[w+" "+next]
+    }
+    return sc
+}
+
+// context words are only used if nothing but a space separates them
+// from the word being checked
+func context(p string, tk []token, i int) (string, string) {
+    prev, next := "", ""
+    if i > 0 && p[tk[i-1].end:tk[i].start] == " " {
+        prev = tk[i-1].word
+    }
+    if i < len(tk)-1 && p[tk[i].end:tk[i+1].start] == " " {
+        next = tk[i+1].word
+    }
+    return prev, next
+}
+
+// jeebies looks for he/be, hut/but and had/bad errors
+// each occurrence is scored in its context against its partner word.
+// if the partner scores higher it is reported with a confidence
+// that the partner word was intended, and returned as a finding
+func Jeebies(pb []string, pl []int, wb []string, jd map[string]int, runlog *[]string, rpt *reporter.Report, fname string) []reporter.Finding {
+    var s []string
+    var rs []string
+    rs = append(rs, "jeebies check")
+
+    s = append(s, fmt.Sprintf("jeebies check report \nstarted: %s\n------------------------------",
+        time.Now().Format(time.RFC850)))
+
+    if len(jd) == 0 {
+        s = append(s, "  no jeebies data in data file. check skipped.")
+        rs = append(rs, "  no jeebies data")
+        if fname != "" {
+            fileio.SaveText(s, fname, true, true)
+        }
+        rpt.Add("jeebies check", 0, s[1:], nil)
+        *runlog = append(*runlog, rs...)
+        return nil
+    }
+
+    partner := make(map[string]string)
+    for _, pr := range pairs {
+        partner[pr[0]] = pr[1]
+        partner[pr[1]] = pr[0]
+    }
+
+    var re = regexp.MustCompile(`[\p{L}'’]+`)
+    found := make(map[string][]string)  // reports, keyed by pair
+    marks := make(map[int][]string)  // suspect words on each line
+    var fs []reporter.Finding
+    total := 0
+    for n, p := range pb {
+        var tk []token
+        for _, loc := range re.FindAllStringIndex(p, -1) {
+            w := strings.ToLower(strings.Replace(p[loc[0]:loc[1]], "’", "'", -1))
+            tk = append(tk, token{w, loc[0], loc[1]})
+        }
+        for i, t := range tk {
+            alt, ok := partner[t.word]
+            if !ok {
+                continue
+            }
+            prev, next := context(p, tk, i)
+            own := score(jd, prev, t.word, next)
+            other := score(jd, prev, alt, next)
+            if other <= own {
+                continue
+            }
+            confidence := 100 * other / (own + other)
+            phrase := strings.TrimSpace(strings.Join([]string{prev, t.word, next}, " "))
+            line := para.Line(wb, pl[n], t.start)
+            key := t.word + "/" + alt
+            found[key] = append(found[key], fmt.Sprintf("  \"%s\" (%s? %d%%)\n  %6d: %s",
+                phrase, alt, confidence, line+1, wb[line]))
+            marks[line] = append(marks[line], p[t.start:t.end])
+            fs = append(fs, reporter.Finding{Check: "jeebies", Severity: reporter.Warning,
+                Line: line + 1, Column: reporter.Column(wb[line], p[t.start:t.end]), Word: p[t.start:t.end],
+                Message: fmt.Sprintf("\"%s\", %s? %d%%", phrase, alt, confidence), Suggestion: alt})
+            total++
+        }
+    }
+
+    for _, pr := range pairs {
+        for _, key := range []string{pr[0] + "/" + pr[1], pr[1] + "/" + pr[0]} {
+            if len(found[key]) == 0 {
+                continue
+            }
+            s = append(s, fmt.Sprintf("%s (%d)", key, len(found[key])))
+            s = append(s, found[key]...)
+            s = append(s, "")
+        }
+    }
+    if total == 0 {
+        s = append(s, "  no jeebies suspects found in text.")
+    }
+
+    rs = append(rs, fmt.Sprintf("  jeebies suspects: %d", total))
+
+    // generate logjeebies.txt from s, unless per-check logs are off
+    if fname != "" {
+        fileio.SaveText(s, fname, true, true)
+    }
+
+    // add to the report, without the log header
+    rpt.Add("jeebies check", total, s[1:], marks)
+
+    // append to pptxt.log
+    *runlog = append(*runlog, rs...)
+    return fs
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 	"pptxt/fileio"
+	"pptxt/reporter"
 	"pptxt/wfreq"
 	"time"
 )
@@ -49,7 +50,8 @@ func minimum(a, b, c int) int {
 // iterate over every suspect word at least six letters long
 // case insensitive
 // looking for a good word in the text that is "near"
-func Levencheck(wb []string, okwords []string, suspects []string, runlog *[]string, fname string) {
+// returns a finding for each occurrence of a suspect word near one
+func Levencheck(wb []string, okwords []string, suspects []string, runlog *[]string, rpt *reporter.Report, fname string) []reporter.Finding {
 	var s []string
 	var rs []string
     rs = append(rs, "Levenshtein checks")
@@ -57,6 +59,8 @@ func Levencheck(wb []string, okwords []string, suspects []string, runlog *[]stri
 	s = append(s, fmt.Sprintf("distance check report \nstarted: %s\n------------------------------",
 		time.Now().Format(time.RFC850)))
     count := 0
+    marks := make(map[int][]string)  // suspect and ok words on each line shown
+    var fs []reporter.Finding  // each occurrence of a suspect word
 
     var wb2 []map[string]struct{}
     _, wb2 = wfreq.GetWordList(wb) // wordlist with word, frequency of word in map, wb2
@@ -83,6 +87,7 @@ func Levencheck(wb []string, okwords []string, suspects []string, runlog *[]stri
 		            	okwordcount += 1
 		            }
 		        }
+				count++  // suspect, ok word pairs reported
 				s = append(s, fmt.Sprintf("%s(%d):%s(%d)", suspect, suspectwordcount, okword, okwordcount))
 
 				// show one line in context
@@ -91,8 +96,13 @@ func Levencheck(wb []string, okwords []string, suspects []string, runlog *[]stri
 		            wordsonthisline := wb2[n] // a set of words on this line
 		            if _, ok := wordsonthisline[suspect]; ok {
 		            	if count == 0 {
-		            		s = append(s, fmt.Sprintf("  %6d: %s", n, line))	
+		            		s = append(s, fmt.Sprintf("  %6d: %s", n+1, line))	
+		            		marks[n] = append(marks[n], suspect)
 		            	}
+		            	w, col := reporter.Locate(line, suspect)  // as written, i.e. with ’
+		            	fs = append(fs, reporter.Finding{Check: "levenshtein", Severity: reporter.Warning,
+		            		Line: n + 1, Column: col, Word: w,
+		            		Message: fmt.Sprintf("close to %s (%d)", okword, okwordcount), Suggestion: okword})
 		                count += 1
 		            }
 		        }
@@ -101,7 +111,8 @@ func Levencheck(wb []string, okwords []string, suspects []string, runlog *[]stri
 		            wordsonthisline := wb2[n] // a set of words on this line
 		            if _, ok := wordsonthisline[okword]; ok {
 		            	if count == 0 {	            	
-		                	s = append(s, fmt.Sprintf("  %6d: %s", n, line))
+		                	s = append(s, fmt.Sprintf("  %6d: %s", n+1, line))
+		                	marks[n] = append(marks[n], okword)
 		                }
 		                count += 1
 		            }
@@ -112,9 +123,15 @@ func Levencheck(wb []string, okwords []string, suspects []string, runlog *[]stri
 
     rs = append(rs, fmt.Sprintf("  suspect words by distance check: %d", count))
 
-    // generate loglev.txt from s
-    fileio.SaveText(s, fname, true, true)
+    // generate loglev.txt from s, unless per-check logs are off
+    if fname != "" {
+        fileio.SaveText(s, fname, true, true)
+    }
+
+    // add to the report, without the log header
+    rpt.Add("Levenshtein check", count, s[1:], marks)
 
     // append to pptxt.log
     *runlog = append(*runlog, rs...)
+    return fs
 }
\ No newline at end of file
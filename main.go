@@ -11,13 +11,18 @@ usage:
     -- scannos
   it looks for a file goodwords.txt or a user-specified filename
   it generates
-    -- report file (default filename report.txt)
+    -- report file (default filename report.txt, or report.json with -format json)
+       line numbers in reports and logs count from 1
     -- if DEBUG: suspects.txt list of words
     -- logfile.txt report of the pptxt run, parameters used, etc.
 main data structures:
   wb working buffer, one text line per slice element
-  wd working dictionary, including goodwords.txt if provided
+  wd working dictionaries, one per -lang language, and goodwords.txt if provided
   pb paragraph buffer, one paragraph per slice element
+  pl paragraph lines, line in wb where each paragraph in pb starts
+  jd jeebies data, phrase counts for he/be, hut/but, had/bad
+  sd scanno data, each scanno with its likely correction
+  vd variant data, sets of spelling variants i.e. color/colour
   sw suspect words list
 */
 
@@ -26,13 +31,15 @@ package main
 import (
     "flag"
     "fmt"
+    "log"
     "os"
+    "pptxt/check"
     "pptxt/fileio"
     "pptxt/dict"
-    "pptxt/leven"
-    "pptxt/spellcheck"
-    "pptxt/textcheck"
-    "sort"
+    "pptxt/para"
+    "pptxt/reporter"
+    "pptxt/rewrap"
+    "strings"
     "time"
     "path/filepath"
 )
@@ -41,20 +48,53 @@ import (
 
 type Params struct {
     infile  string
+    outfile string
+    htmlfile string
+    format  string
+    logs    bool
     datfile string
     gwfilename string
     experimental bool
     useBOM  bool
     useCRLF bool
+    langs   listFlag
+    langspans bool
+    longline  int
+    shortline int
+    rewrapfile string
+    allowmarkup listFlag
+    checks  listFlag
+    skip    listFlag
+    listchecks bool
+}
+
+// a repeatable list flag, i.e. -lang en,fr or -lang en -lang fr
+type listFlag []string
+
+func (l *listFlag) String() string {
+    return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+    for _, item := range strings.Split(value, ",") {
+        if item = strings.TrimSpace(item); item != "" {
+            *l = append(*l, item)
+        }
+    }
+    return nil
 }
 
 var p Params
 var runlog []string  // logfile for pptxt
-var report []string  // report for pptxt
+var report reporter.Report  // report for pptxt, the results of every check
 var wlm []string  // suspect words returned as list by spellcheck
 var wb []string  // working buffer
-var wd []string  // working dictionary inc. goodwords.txt
+var wd []dict.Dictionary  // working dictionaries inc. goodwords.txt
+var jd map[string]int  // jeebies phrase counts
+var sd map[string]string  // scannos and corrections
+var vd [][]string  // spelling variant sets
 var sw []string  // suspect words list
+var checks []check.Check  // the checks to run
 
 func Test(p Params) {
     // fmt.Println(p.experimental)
@@ -63,12 +103,43 @@ func Test(p Params) {
 func doParams() Params {
     p := Params{}
     flag.StringVar(&p.infile, "i", "", "input file")
+    flag.StringVar(&p.outfile, "o", "report.txt", "report file")
+    flag.StringVar(&p.htmlfile, "html", "", "also save the report as HTML to this file")
+    flag.StringVar(&p.format, "format", "text", "report format: text, json or jsonl")
+    flag.BoolVar(&p.logs, "logs", true, "also save each check's results to its own log file")
     flag.StringVar(&p.datfile, "d", "pptxt.dat", "data file")
     flag.StringVar(&p.gwfilename, "g", "goodwords.txt", "good word list")
     flag.BoolVar(&p.experimental, "x", false, "experimental (developers only)")
     flag.BoolVar(&p.useBOM, "useBOM", false, "use BOM on text output")
     flag.BoolVar(&p.useCRLF, "useCRLF", false, "CRLF line endings on output")
+    flag.Var(&p.langs, "lang", "dictionary languages, i.e. en,fr (default en); the first is the book's main language")
+    flag.BoolVar(&p.langspans, "langspans", false, "check words in <lang xx> spans against that language, others only against the first -lang")
+    flag.IntVar(&p.longline, "long", 72, "report lines longer than this; the -rewrap width")
+    flag.IntVar(&p.shortline, "short", 55, "report lines shorter than this within a paragraph; -rewrap keeps paragraphs of them as poetry")
+    flag.StringVar(&p.rewrapfile, "rewrap", "", "save text with paragraphs rewrapped to this file")
+    flag.Var(&p.allowmarkup, "allowmarkup", "markup classes not to report, i.e. illustration,thoughtbreak")
+    flag.Var(&p.checks, "checks", "run only these checks, i.e. spellcheck,jeebies (default all)")
+    flag.Var(&p.skip, "skip", "checks not to run, i.e. long-lines,short-lines")
+    flag.BoolVar(&p.listchecks, "list-checks", false, "list the checks and exit")
     flag.Parse()
+    if len(p.langs) == 0 {
+        p.langs = listFlag{"en"}
+    }
+    if p.longline < 1 || p.shortline < 1 {
+        log.Fatalf("-long and -short must be at least 1: %d, %d", p.longline, p.shortline)
+    }
+    switch p.format {
+    case "text":
+    case "json", "jsonl":
+        // report.json or report.jsonl unless -o names the file
+        named := false
+        flag.Visit(func(f *flag.Flag) { named = named || f.Name == "o" })
+        if !named {
+            p.outfile = "report." + p.format
+        }
+    default:
+        log.Fatalf("unknown -format %s: use text, json or jsonl", p.format)
+    }
     return p
 }
 
@@ -80,6 +151,16 @@ func main() {
 
     p = doParams()  // parse command line parameters
 
+    if p.listchecks {
+        for _, c := range check.All() {
+            fmt.Printf("  %-18s %s\n", c.Name(), c.Description())
+        }
+        return
+    }
+    var err error
+    checks, err = check.Select(p.checks, p.skip)
+    if err != nil { log.Fatal(err) }
+
     /*************************************************************************/
     /* working buffer (wb)                                                   */
     /* user-supplied source file UTF-8 encoded                               */
@@ -94,101 +175,133 @@ func main() {
     runlog = append(runlog, fmt.Sprintf("project is in: %s", loc_proj))
 
     /*************************************************************************/
-    /* working dictionary (wd)                                               */
-    /* create from words in dictionary (in pptxt.dat file)                   */
-    /* and words from optional project-specific goodwords.txt file           */
-    /* result are all known good words in a sorted list                      */
+    /* data file sections (ds)                                               */
+    /* pptxt.dat holds named sections: DICT, DICT:xx, JEEBIES, SCANNOS,      */
+    /* VARIANTS                                                              */
+    /* each check requests the section it needs                              */
     /*************************************************************************/
 
-    // default dictionary is in the pptxt.dat file
     // search in same folder as executable; if not there, search project folder
+    datpath := ""
     if _, err := os.Stat(filepath.Join(loc_exec, p.datfile)); !os.IsNotExist(err) {
-        // it exists
-        wd = dict.ReadDict(filepath.Join(loc_exec, p.datfile))
-        runlog = append(runlog, 
-            fmt.Sprintf("datafile: %s",filepath.Join(loc_exec, p.datfile)))
-    }
-    if len(wd) == 0 {
-        if _, err := os.Stat(filepath.Join(loc_proj, p.datfile)); !os.IsNotExist(err) { 
-            // it exists
-            wd = dict.ReadDict(filepath.Join(loc_proj, p.datfile))
-            runlog = append(runlog, 
-                fmt.Sprintf("datafile: %s",filepath.Join(loc_proj, p.datfile)))
-        }
+        datpath = filepath.Join(loc_exec, p.datfile)  // it exists
+    } else if _, err := os.Stat(filepath.Join(loc_proj, p.datfile)); !os.IsNotExist(err) {
+        datpath = filepath.Join(loc_proj, p.datfile)  // it exists
     }
-    if len(wd) == 0 {
-        runlog = append(runlog, fmt.Sprintf("no dictionary present"))
+    ds := dict.Sections{}
+    if datpath != "" {
+        var err error
+        ds, err = dict.ReadSections(datpath)
+        if err != nil { log.Fatal(err) }
+        runlog = append(runlog, fmt.Sprintf("datafile: %s", datpath))
     } else {
-        runlog = append(runlog, fmt.Sprintf("dictionary present: %d words", len(wd)))
+        runlog = append(runlog, fmt.Sprintf("no %s found.", p.datfile))
+    }
+
+    /*************************************************************************/
+    /* working dictionaries (wd)                                             */
+    /* create from the DICT section of each selected language                */
+    /* (in pptxt.dat file) and words from optional project-specific          */
+    /* goodwords.txt file. each is a sorted list of known good words         */
+    /*************************************************************************/
+
+    for _, lang := range p.langs {
+        d, err := ds.Dict(lang)
+        if err != nil || len(d.Words) == 0 {
+            runlog = append(runlog, fmt.Sprintf("no %s dictionary present: add a DICT:%s section to %s", lang, lang, p.datfile))
+            continue
+        }
+        wd = append(wd, d)
+        runlog = append(runlog, fmt.Sprintf("%s dictionary present: %d words", lang, len(d.Words)))
     }
-    wl := []string{}
     if len(p.gwfilename) > 0 { // a good word list was specified or default accepted
         if _, err := os.Stat(p.gwfilename); !os.IsNotExist(err) {  // it exists
-            wl = dict.ReadWordList(filepath.Join(loc_proj,p.gwfilename))
+            wl := dict.ReadWordList(filepath.Join(loc_proj,p.gwfilename))
             runlog = append(runlog, fmt.Sprintf("good word list: %d words", len(wl)))
-            wd = append(wd, wl...)  // add goodwords into dictionary
+            wd = append(wd, dict.NewDictionary(dict.GoodWords, wl))
         } else {  // it does not exist
             runlog = append(runlog, fmt.Sprintf("no %s found.", p.gwfilename))
         }
     }
-    // need the words in a sorted list for binary search later
-    if len(wl) > 0 {
-        sort.Strings(wd)  // appended wordlist needs sorting
+
+    /*************************************************************************/
+    /* jeebies data (jd)                                                     */
+    /* phrase counts from the pptxt.dat file                                 */
+    /*************************************************************************/
+
+    if lines, err := ds.Block("JEEBIES"); err == nil {
+        jd = dict.Jeebies(lines)
+        runlog = append(runlog, fmt.Sprintf("jeebies phrases: %d", len(jd)))
+    } else {
+        runlog = append(runlog, fmt.Sprintf("jeebies: %s", err))
     }
 
     /*************************************************************************/
-    /* paragraph buffer (pb)                                                 */
-    /* the user source file one paragraph per line                           */
+    /* scanno data (sd)                                                      */
+    /* scannos and their corrections from the pptxt.dat file                 */
     /*************************************************************************/
 
-    var cp string  // current (in progress) paragraph
-    var pb []string  // paragraph buffer
-    for _, element := range wb {
-        // if this is a blank line and there is a paragraph in progress, save it
-        // if not a blank line, put it into the current paragraph
-        if element == "" {
-            if len(cp) > 0 {
-                pb = append(pb, cp) // save this paragraph
-                cp = cp[:0] // empty the current paragraph buffer
-            }
-        } else {
-            if len(cp) == 0 {
-                cp += element
-            } else {
-                cp = cp + " " + element
-            }
-        }
+    if lines, err := ds.Block("SCANNOS"); err == nil {
+        sd = dict.Scannos(lines)
+        runlog = append(runlog, fmt.Sprintf("scannos: %d", len(sd)))
+    } else {
+        runlog = append(runlog, fmt.Sprintf("scannos: %s", err))
     }
-    // finished processing all lines in the file
-    // flush possible non-empty current paragraph buffer    
-    if len(cp) > 0 {
-        pb = append(pb, cp) // save this paragraph
+
+    /*************************************************************************/
+    /* variant data (vd)                                                     */
+    /* sets of spelling variants from the pptxt.dat file                     */
+    /*************************************************************************/
+
+    if lines, err := ds.Block("VARIANTS"); err == nil {
+        vd = dict.Variants(lines)
+        runlog = append(runlog, fmt.Sprintf("spelling variant sets: %d", len(vd)))
+    } else {
+        runlog = append(runlog, fmt.Sprintf("spelling variants: %s", err))
     }
-    runlog = append(runlog, fmt.Sprintf("paragraphs: %d", len(pb)))
 
     /*************************************************************************/
-    /* begin individual tests                                                */
+    /* paragraph buffer (pb)                                                 */
+    /* the user source file one paragraph per line                           */
+    /* with the line each paragraph starts on in pl                          */
     /*************************************************************************/
 
-    // spellcheck
-    // generates report in logspell.txt
-    // returns list of suspect words, ok words used in text
-    sw, okwords := spellcheck.Spellcheck(wb, wd, &runlog, "logspell.txt")
+    pb, pl := para.Build(wb)
+    runlog = append(runlog, fmt.Sprintf("paragraphs: %d", len(pb)))
+
+    // optionally save the text with ordinary paragraphs rewrapped
+    if p.rewrapfile != "" {
+        fileio.SaveText(rewrap.Rewrap(pb, pl, wb, p.longline, p.shortline), p.rewrapfile, p.useBOM, p.useCRLF)
+        runlog = append(runlog, fmt.Sprintf("rewrapped to %d: %s", p.longline, p.rewrapfile))
+    }
 
-    // levenshtein check
-    // compares all suspect words to all okwords in text
-    // generates report in loglev.txt
-    leven.Levencheck(wb, okwords, sw, &runlog, "loglev.txt")
+    /*************************************************************************/
+    /* begin individual tests                                                */
+    /* the checks run in registry order, less any -skip or not in -checks    */
+    /* each adds its section to the report and may save its own log file     */
+    /*************************************************************************/
 
-    // text check
-    // 
-    // generates report in logtext.txt
-    textcheck.Textcheck(pb, wb, &runlog, "logtext.txt")    
+    book := check.Book{Wb: wb, Pb: pb, Pl: pl, Wd: wd, Jd: jd, Sd: sd, Vd: vd,
+        LangSpans: p.langspans, Long: p.longline, Short: p.shortline, AllowMarkup: p.allowmarkup,
+        Logs: p.logs, Runlog: &runlog, Report: &report}
+    check.Run(&book, checks)
+    sw = book.Suspects
 
     /*************************************************************************/
     /* all tests complete. save results to specified report file and logfile */
     /*************************************************************************/
 
+    if p.format == "text" {
+        report.Save(p.infile, p.outfile, p.useBOM, p.useCRLF)
+    } else {
+        report.SaveJSON(p.infile, p.outfile, p.format)
+    }
+    runlog = append(runlog, fmt.Sprintf("report: %s", p.outfile))
+    if p.htmlfile != "" {
+        report.SaveHTML(p.infile, wb, p.htmlfile, p.useBOM, p.useCRLF)
+        runlog = append(runlog, fmt.Sprintf("HTML report: %s", p.htmlfile))
+    }
+
     fileio.SaveText(runlog, "logpptxt.txt", p.useBOM, p.useCRLF)
 
     // remaining words in sw are suspects. conditionally generate a report
@@ -0,0 +1,74 @@
+package para
+
+import (
+    "strings"
+    "unicode/utf8"
+)
+
+/*  the paragraph buffer (pb) is the working buffer (wb) with each
+    paragraph joined into one string, lines separated by one space.
+    pl holds, 1:1 with pb, the index in wb of each paragraph's first line
+    so a check that finds something in a paragraph can report the line.
+    */
+
+// builds the paragraph buffer and paragraph start lines from wb
+func Build(wb []string) ([]string, []int) {
+    var cp string  // current (in progress) paragraph
+    var pb []string  // paragraph buffer
+    var pl []int  // line in wb where each paragraph starts
+    start := 0
+    for n, element := range wb {
+        // if this is a blank line and there is a paragraph in progress, save it
+        // if not a blank line, put it into the current paragraph
+        if element == "" {
+            if len(cp) > 0 {
+                pb = append(pb, cp) // save this paragraph
+                pl = append(pl, start)
+                cp = cp[:0] // empty the current paragraph buffer
+            }
+        } else {
+            if len(cp) == 0 {
+                cp += element
+                start = n
+            } else {
+                cp = cp + " " + element
+            }
+        }
+    }
+    // finished processing all lines in the file
+    // flush possible non-empty current paragraph buffer
+    if len(cp) > 0 {
+        pb = append(pb, cp) // save this paragraph
+        pl = append(pl, start)
+    }
+    return pb, pl
+}
+
+// returns the line in wb holding byte offset off of the paragraph
+// that starts on line start
+func Line(wb []string, start int, off int) int {
+    n := start
+    for n < len(wb)-1 && off > len(wb[n]) {
+        off -= len(wb[n]) + 1  // the line plus the joining space
+        n++
+    }
+    return n
+}
+
+// true if the lines of a paragraph are indented or are mostly shorter
+// than short, as poetry is. the last line is expected to be short and
+// is not counted. it takes two short lines, so a short line followed by
+// a full one is broken wrapping, not poetry. the short line check and
+// -rewrap both use this, so what one reports the other will fix
+func Verse(lines []string, short int) bool {
+    shortcount := 0
+    for n, line := range lines {
+        if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+            return true
+        }
+        if n < len(lines)-1 && utf8.RuneCountInString(line) < short {
+            shortcount++
+        }
+    }
+    return shortcount > 1 && 2*shortcount > len(lines)-1
+}
@@ -0,0 +1,27 @@
+package para
+
+import (
+    "testing"
+)
+
+// poetry takes two or more short lines, or an indent
+func TestVerse(t *testing.T) {
+    full := "and this following line is long enough to count as a full line."
+    tests := []struct {
+        name  string
+        lines []string
+        want  bool
+    }{
+        {"prose", []string{full, full, "The end."}, false},
+        {"one short line", []string{"A short first line,", full}, false},
+        {"short line then full", []string{"A short first line,", full, "The end."}, false},
+        {"one line", []string{"A heading"}, false},
+        {"poetry", []string{"The sea is calm,", "the tide is full,", "the moon lies fair"}, true},
+        {"indented", []string{full, "  indented", full}, true},
+    }
+    for _, tt := range tests {
+        if got := Verse(tt.lines, 30); got != tt.want {
+            t.Errorf("%s: Verse = %v, want %v", tt.name, got, tt.want)
+        }
+    }
+}
@@ -0,0 +1,317 @@
+package reporter
+
+import (
+    "encoding/json"
+    "fmt"
+    "html"
+    "pptxt/fileio"
+    "regexp"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+    "unicode"
+    "unicode/utf8"
+)
+
+// the results of one check, as written to its own log
+// Marks holds, by index in wb, the text the check found on that line
+// LineMarks holds, by index in Lines, the text to highlight in that
+// line of the results only, in place of its Marks
+// line numbers in Lines, as in every report, count from 1
+type Section struct {
+    Name      string  // i.e. "spellcheck"
+    Count     int  // number of things reported
+    Lines     []string
+    Marks     map[int][]string
+    LineMarks map[int][]string
+}
+
+// a single problem found by a check, for other tools to read
+// Line and Column count from 1, Column in characters. either is 0 if
+// the finding is not tied to a place in the text
+type Finding struct {
+    Check      string `json:"check"`  // i.e. "spellcheck"
+    Severity   string `json:"severity"`
+    Line       int    `json:"line,omitempty"`
+    Column     int    `json:"column,omitempty"`
+    Word       string `json:"word,omitempty"`  // the text found
+    Message    string `json:"message"`
+    Suggestion string `json:"suggestion,omitempty"`
+}
+
+// severities of findings
+const (
+    Error   = "error"  // almost certainly wrong
+    Warning = "warning"  // probably wrong
+    Info    = "info"  // worth a look
+)
+
+// the results of every check in the order they ran
+type Report struct {
+    Sections []Section
+    Findings []Finding
+}
+
+// add the results of a check to the report
+// marks may be nil if there is nothing to highlight
+func (r *Report) Add(name string, count int, lines []string, marks map[int][]string) {
+    r.AddSection(Section{Name: name, Count: count, Lines: lines, Marks: marks})
+}
+
+// add the results of a check, with any LineMarks, to the report
+func (r *Report) AddSection(sec Section) {
+    r.Sections = append(r.Sections, sec)
+}
+
+// add findings to the report
+func (r *Report) Find(fs ...Finding) {
+    r.Findings = append(r.Findings, fs...)
+}
+
+// save the findings as JSON, one document, or JSONL, one finding per
+// line, in order of line and column in the text
+func (r *Report) SaveJSON(infile string, outfile string, format string) {
+    fs := make([]Finding, len(r.Findings))
+    copy(fs, r.Findings)
+    sort.SliceStable(fs, func(i, j int) bool {
+        if fs[i].Line != fs[j].Line {
+            return fs[i].Line < fs[j].Line
+        }
+        return fs[i].Column < fs[j].Column
+    })
+    var s []string
+    if format == "jsonl" {
+        for _, f := range fs {
+            b, _ := json.Marshal(f)
+            s = append(s, string(b))
+        }
+    } else {
+        b, _ := json.MarshalIndent(struct {
+            File     string    `json:"file"`
+            Findings []Finding `json:"findings"`
+        }{infile, fs}, "", "  ")
+        s = append(s, string(b))
+    }
+    fileio.SaveText(s, outfile, false, false)  // no BOM, which JSON readers reject
+}
+
+// save the report as one file: a table of contents with the count
+// for each check, then each check's results in order
+func (r *Report) Save(infile string, outfile string, useBOM bool, useCRLF bool) {
+    var s []string
+    s = append(s, fmt.Sprintf("pptxt report for %s\ngenerated: %s\n------------------------------",
+        infile, time.Now().Format(time.RFC850)))
+    s = append(s, "contents")
+    for n, sec := range r.Sections {
+        dots := ".."
+        if len(sec.Name) < 38 {
+            dots = strings.Repeat(".", 40-len(sec.Name))
+        }
+        s = append(s, fmt.Sprintf("  %2d. %s %s %d", n+1, sec.Name, dots, sec.Count))
+    }
+    s = append(s, "------------------------------")
+    for n, sec := range r.Sections {
+        s = append(s, "")
+        s = append(s, fmt.Sprintf("%d. %s (%d)", n+1, sec.Name, sec.Count))
+        s = append(s, "------------------------------")
+        s = append(s, sec.Lines...)
+    }
+    fileio.SaveText(s, outfile, useBOM, useCRLF)
+}
+
+// a line of a check's results referring to a line of the text,
+// i.e. "  123: text" or "  123: (87) text", wb[122]
+var quoted = regexp.MustCompile(`^(\s*)(\d+): (.*)$`)
+
+// true if r is a letter or number, which a word mark must not touch
+func wordRune(r rune) bool {
+    return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+// byte offsets of the first match of mark in s, -1, -1 if none
+// an apostrophe in mark matches either ' or ’, as words are compared
+// with ’ made ' (see wfreq)
+func index(s string, mark string) (int, int) {
+    if !strings.ContainsAny(mark, "'’") {
+        if at := strings.Index(s, mark); at >= 0 {
+            return at, at + len(mark)
+        }
+        return -1, -1
+    }
+    re := regexp.MustCompile(strings.NewReplacer("'", "['’]", "’", "['’]").Replace(regexp.QuoteMeta(mark)))
+    if loc := re.FindStringIndex(s); loc != nil {
+        return loc[0], loc[1]
+    }
+    return -1, -1
+}
+
+// byte offsets of the start and end of the next match of mark in line
+// at or after i, -1, -1 if none. marks starting or ending with a letter
+// only match whole words
+func next(line string, mark string, i int) (int, int) {
+    first, _ := utf8.DecodeRuneInString(mark)
+    last, _ := utf8.DecodeLastRuneInString(mark)
+    for ; i < len(line); i++ {
+        at, end := index(line[i:], mark)
+        if at < 0 {
+            break
+        }
+        at, end = at+i, end+i
+        before, _ := utf8.DecodeLastRuneInString(line[:at])
+        after, _ := utf8.DecodeRuneInString(line[end:])
+        if !(wordRune(first) && at > 0 && wordRune(before)) &&
+            !(wordRune(last) && end < len(line) && wordRune(after)) {
+            return at, end
+        }
+        i = at
+    }
+    return -1, -1
+}
+
+// the column, counting characters from 1, of byte offset at in line
+func At(line string, at int) int {
+    return utf8.RuneCountInString(line[:at]) + 1
+}
+
+// the column of the first match of text in line, as a word if it
+// starts or ends with a letter, 0 if not found
+func Column(line string, text string) int {
+    if cols := Columns(line, text); len(cols) > 0 {
+        return cols[0]
+    }
+    return 0
+}
+
+// the columns of every match of text in line, as for Column
+func Columns(line string, text string) []int {
+    var cols []int
+    if text == "" {
+        return cols
+    }
+    for at, _ := next(line, text, 0); at >= 0; at, _ = next(line, text, at+1) {
+        cols = append(cols, At(line, at))
+    }
+    return cols
+}
+
+// text as it is written in line, i.e. with ’ where text has ', and its
+// column as for Column. text and 0 if not found
+func Locate(line string, text string) (string, int) {
+    if text == "" {
+        return text, 0
+    }
+    at, end := next(line, text, 0)
+    if at < 0 {
+        return text, 0
+    }
+    return line[at:end], At(line, at)
+}
+
+// byte offsets of the next mark to highlight in line at or after i, -1,
+// -1 if none. a single character, i.e. é, matches anywhere, even in a
+// word; other marks match as for next
+func nextMark(line string, mark string, i int) (int, int) {
+    if utf8.RuneCountInString(mark) > 1 {
+        return next(line, mark, i)
+    }
+    if i > len(line) {
+        return -1, -1
+    }
+    if at := strings.Index(line[i:], mark); at >= 0 {
+        return at + i, at + i + len(mark)
+    }
+    return -1, -1
+}
+
+// the line, HTML escaped, with each mark highlighted
+// marks of more than one character starting or ending with a letter
+// only match whole words
+func highlight(line string, marks []string) string {
+    on := make([]bool, len(line)+1)  // bytes to highlight
+    for _, mark := range marks {
+        if mark == "" {
+            continue
+        }
+        for at, end := nextMark(line, mark, 0); at >= 0; at, end = nextMark(line, mark, at+1) {
+            for j := at; j < end; j++ {
+                on[j] = true
+            }
+        }
+    }
+    var b strings.Builder
+    for i := 0; i < len(line); {
+        j := i
+        for j < len(line) && on[j] == on[i] {
+            j++
+        }
+        if on[i] {
+            b.WriteString("<mark>" + html.EscapeString(line[i:j]) + "</mark>")
+        } else {
+            b.WriteString(html.EscapeString(line[i:j]))
+        }
+        i = j
+    }
+    return b.String()
+}
+
+const style = `body { font-family: sans-serif; margin: 1em; }
+pre { margin: 0.2em 0 0.5em 1em; }
+summary { cursor: pointer; font-weight: bold; padding: 0.2em 0; }
+summary .count { font-weight: normal; color: #666; }
+mark { background: #fd6; }
+a { color: #036; }
+#source { height: 40vh; overflow: auto; border: 1px solid #999; padding: 0.5em; }
+#source span:target { background: #fd6; }
+.lineno { color: #999; }`
+
+// save the report as one self-contained HTML file. each check is a
+// collapsible section; text it found is highlighted in each quoted line
+// and line numbers link to a copy of the text at the end of the page
+func (r *Report) SaveHTML(infile string, wb []string, outfile string, useBOM bool, useCRLF bool) {
+    var s []string
+    s = append(s, "<!DOCTYPE html>")
+    s = append(s, "<html><head><meta charset=\"utf-8\">")
+    s = append(s, fmt.Sprintf("<title>pptxt report for %s</title>", html.EscapeString(infile)))
+    s = append(s, "<style>\n"+style+"\n</style></head><body>")
+    s = append(s, fmt.Sprintf("<h1>pptxt report for %s</h1>", html.EscapeString(infile)))
+    s = append(s, fmt.Sprintf("<p>generated: %s</p>", time.Now().Format(time.RFC850)))
+    for n, sec := range r.Sections {
+        s = append(s, fmt.Sprintf("<details><summary>%d. %s <span class=\"count\">(%d)</span></summary><pre>",
+            n+1, html.EscapeString(sec.Name), sec.Count))
+        for i, entry := range sec.Lines {
+            for _, line := range strings.Split(entry, "\n") {
+                m := quoted.FindStringSubmatch(line)
+                if m == nil {
+                    s = append(s, html.EscapeString(line))
+                    continue
+                }
+                ln, err := strconv.Atoi(m[2])  // counts from 1
+                if err != nil || ln < 1 || ln > len(wb) {
+                    s = append(s, html.EscapeString(line))
+                    continue
+                }
+                marks := sec.Marks[ln-1]
+                if lm, ok := sec.LineMarks[i]; ok {
+                    marks = lm
+                }
+                rest := html.EscapeString(m[3])
+                if text := wb[ln-1]; strings.HasSuffix(m[3], text) {  // quotes the line
+                    cut := len(m[3]) - len(text)
+                    rest = html.EscapeString(m[3][:cut]) + highlight(text, marks)
+                }
+                s = append(s, fmt.Sprintf("%s<a href=\"#L%d\">%s</a>: %s", m[1], ln, m[2], rest))
+            }
+        }
+        s = append(s, "</pre></details>")
+    }
+    s = append(s, fmt.Sprintf("<h2>%s</h2>", html.EscapeString(infile)))
+    s = append(s, "<div id=\"source\"><pre>")
+    for n, line := range wb {
+        s = append(s, fmt.Sprintf("<span id=\"L%d\"><span class=\"lineno\">%6d</span>  %s</span>",
+            n+1, n+1, html.EscapeString(line)))
+    }
+    s = append(s, "</pre></div>")
+    s = append(s, "</body></html>")
+    fileio.SaveText(s, outfile, useBOM, useCRLF)
+}
@@ -0,0 +1,77 @@
+package reporter
+
+import (
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+// single characters are highlighted inside words, longer marks only
+// as whole words
+func TestHighlight(t *testing.T) {
+    tests := []struct {
+        line  string
+        marks []string
+        want  string
+    }{
+        {"The café was open.", []string{"é"}, "The caf<mark>é</mark> was open."},
+        {"cœur and œil", []string{"œ"}, "c<mark>œ</mark>ur and <mark>œ</mark>il"},
+        {"the theme then", []string{"the"}, "<mark>the</mark> theme then"},
+        {"a * b", []string{"*"}, "a <mark>*</mark> b"},
+        {"<i>x</i>", []string{"<i>"}, "<mark>&lt;i&gt;</mark>x&lt;/i&gt;"},
+    }
+    for _, tt := range tests {
+        if got := highlight(tt.line, tt.marks); got != tt.want {
+            t.Errorf("highlight(%q, %q) = %q, want %q", tt.line, tt.marks, got, tt.want)
+        }
+    }
+}
+
+// each line of the results highlights its own LineMarks
+func TestSaveHTMLLineMarks(t *testing.T) {
+    wb := []string{"The café was open."}
+    var r Report
+    r.AddSection(Section{Name: "character checks", Count: 2,
+        Lines:     []string{"  'é'", "    1: The café was open.", "  '.'", "    1: The café was open."},
+        Marks:     map[int][]string{0: {"é", "."}},
+        LineMarks: map[int][]string{1: {"é"}, 3: {"."}}})
+    fname := filepath.Join(t.TempDir(), "report.html")
+    r.SaveHTML("book.txt", wb, fname, false, false)
+    b, err := os.ReadFile(fname)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, want := range []string{
+        `<a href="#L1">1</a>: The caf<mark>é</mark> was open.`,
+        `<a href="#L1">1</a>: The café was open<mark>.</mark>`,
+    } {
+        if !strings.Contains(string(b), want) {
+            t.Errorf("report lacks %q", want)
+        }
+    }
+}
+
+// words compared with ’ made ' are found as they are written
+func TestLocate(t *testing.T) {
+    tests := []struct {
+        line, text string
+        want       string
+        col        int
+    }{
+        {"the fo’c’s’le was dark", "fo'c's'le", "fo’c’s’le", 5},
+        {"the colour’s depth", "colour's", "colour’s", 5},
+        {"don't and don’t", "don't", "don't", 1},
+        {"the other them", "the", "the", 1},
+        {"nothing here", "absent", "absent", 0},
+    }
+    for _, tt := range tests {
+        got, col := Locate(tt.line, tt.text)
+        if got != tt.want || col != tt.col {
+            t.Errorf("Locate(%q, %q) = %q, %d, want %q, %d", tt.line, tt.text, got, col, tt.want, tt.col)
+        }
+    }
+    if got := highlight("the fo’c’s’le", []string{"fo'c's'le"}); got != "the <mark>fo’c’s’le</mark>" {
+        t.Errorf("highlight = %q", got)
+    }
+}
@@ -0,0 +1,87 @@
+package rewrap
+
+import (
+    "pptxt/para"
+    "strings"
+    "unicode/utf8"
+)
+
+// a paragraph is left as it is if any line is indented, if it looks
+// like a table or if it is poetry as the short line check sees it, with
+// lines shorter than short, and none over the width. a line over the
+// width is badly wrapped prose
+func ordinary(lines []string, width int, short int) bool {
+    long := false
+    for _, line := range lines {
+        if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+            return false  // indented block
+        }
+        if strings.Contains(strings.TrimSpace(line), "  ") || strings.Contains(line, "|") {
+            return false  // table, columns separated by spaces or bars
+        }
+        long = long || utf8.RuneCountInString(line) > width
+    }
+    return long || !para.Verse(lines, short)
+}
+
+// fill a paragraph into lines of at most width runes
+// a word longer than width is put on a line by itself
+func fill(p string, width int) []string {
+    var lines []string
+    line := ""
+    for _, word := range strings.Fields(p) {
+        if line == "" {
+            line = word
+        } else if utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width {
+            line = line + " " + word
+        } else {
+            lines = append(lines, line)
+            line = word
+        }
+    }
+    if line != "" {
+        lines = append(lines, line)
+    }
+    return lines
+}
+
+// rewrap returns the text with ordinary paragraphs re-wrapped to width
+// blank lines, indented blocks, poetry and tables are untouched
+// as is anything in /* */ or /# #/ no-rewrap markup
+// short is the -short width, below which lines may be poetry
+func Rewrap(pb []string, pl []int, wb []string, width int, short int) []string {
+    var out []string
+    n := 0  // next line of wb to copy
+    nowrap := false  // inside /* */ or /# #/
+    for i, start := range pl {
+        for ; n < start; n++ {
+            out = append(out, wb[n])  // blank lines between paragraphs
+        }
+        end := start  // one past the last line of the paragraph
+        for end < len(wb) && wb[end] != "" {
+            end++
+        }
+        lines := wb[start:end]
+        keep := nowrap  // inside markup opened in an earlier paragraph
+        for _, line := range lines {
+            switch strings.TrimSpace(line) {
+            case "/*", "/#":
+                nowrap = true
+                keep = true
+            case "*/", "#/":
+                nowrap = false
+                keep = true
+            }
+        }
+        if keep || !ordinary(lines, width, short) {
+            out = append(out, lines...)
+        } else {
+            out = append(out, fill(pb[i], width)...)
+        }
+        n = end
+    }
+    for ; n < len(wb); n++ {
+        out = append(out, wb[n])  // trailing blank lines
+    }
+    return out
+}
@@ -0,0 +1,44 @@
+package rewrap
+
+import (
+    "testing"
+)
+
+// paragraphs the short line check reports are rewrapped, poetry,
+// indented blocks and tables are not
+func TestOrdinary(t *testing.T) {
+    tests := []struct {
+        name  string
+        lines []string
+        want  bool
+    }{
+        {"prose", []string{
+            "It was the best of times, it was the worst of times, it was the",
+            "age of wisdom, it was the age of foolishness, it was the epoch of",
+            "belief."}, true},
+        {"one broken line", []string{
+            "It was the best of times, it was the worst of times, it was the",
+            "age of wisdom, it was",
+            "the age of foolishness, it was the epoch of belief, it was the epoch."}, true},
+        {"short lines and a long one", []string{
+            "It was the best",
+            "of times, it was",
+            "the worst of times, it was the age of wisdom, it was the age of foolishness."}, true},
+        {"one line", []string{"A short paragraph."}, true},
+        {"poetry", []string{
+            "The sea is calm to-night.",
+            "The tide is full, the moon lies fair",
+            "Upon the straits;"}, false},
+        {"indented", []string{
+            "  The sea is calm to-night, the tide is full, the moon lies fair",
+            "  upon the straits."}, false},
+        {"table", []string{
+            "Apples      3",
+            "Pears       4"}, false},
+    }
+    for _, tt := range tests {
+        if got := ordinary(tt.lines, 72, 55); got != tt.want {
+            t.Errorf("%s: ordinary = %v, want %v", tt.name, got, tt.want)
+        }
+    }
+}
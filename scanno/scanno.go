@@ -0,0 +1,115 @@
+package scanno
+
+import (
+    "fmt"
+    "pptxt/fileio"
+    "pptxt/reporter"
+    "pptxt/wfreq"
+    "sort"
+    "strings"
+    "time"
+    "unicode"
+)
+
+// match the case of the correction to the case of the scanno
+// "Tbe" suggests "The", "TBE" suggests "THE"
+func suggest(word string, fix string) string {
+    if word == strings.ToUpper(word) && len([]rune(word)) > 1 {
+        return strings.ToUpper(fix)
+    }
+    r := []rune(word)
+    if unicode.IsUpper(r[0]) {
+        f := []rune(fix)
+        f[0] = unicode.ToUpper(f[0])
+        return string(f)
+    }
+    return fix
+}
+
+// scanno check flags every occurrence of a known scanno
+// these are often real words so they pass the spellcheck
+// sd maps each scanno to its likely correction
+// returns a finding for each occurrence, suggesting the correction
+func Scannocheck(wb []string, sd map[string]string, runlog *[]string, rpt *reporter.Report, fname string) []reporter.Finding {
+    var s []string
+    var rs []string
+    rs = append(rs, "scanno check")
+
+    s = append(s, fmt.Sprintf("scanno check report \nstarted: %s\n------------------------------",
+        time.Now().Format(time.RFC850)))
+
+    if len(sd) == 0 {
+        s = append(s, "  no scannos in data file. check skipped.")
+        rs = append(rs, "  no scanno data")
+        if fname != "" {
+            fileio.SaveText(s, fname, true, true)
+        }
+        rpt.Add("scanno check", 0, s[1:], nil)
+        *runlog = append(*runlog, rs...)
+        return nil
+    }
+
+    var wb2 []map[string]struct{}
+    _, wb2 = wfreq.GetWordList(wb) // wordlist with word, frequency of word in map, wb2
+
+    found := make(map[string][]string)  // lines for each scanno as it appears in text
+    occurs := make(map[string]int)  // occurrences of each, more than one a line may have
+    marks := make(map[int][]string)  // scannos on each line
+    var fs []reporter.Finding
+    for n, line := range wb {
+        var words []string
+        for word := range wb2[n] {
+            if _, ok := sd[strings.ToLower(word)]; ok {
+                words = append(words, word)
+            }
+        }
+        sort.Strings(words)  // stable order when a line has more than one
+        for _, word := range words {
+            found[word] = append(found[word], fmt.Sprintf("  %6d: %s", n+1, line))
+            marks[n] = append(marks[n], word)
+            fix := suggest(word, sd[strings.ToLower(word)])
+            cols := reporter.Columns(line, word)
+            if len(cols) == 0 {
+                cols = []int{0}  // count it once if not found as a whole word
+            }
+            written, _ := reporter.Locate(line, word)  // as written, i.e. with ’
+            for _, col := range cols {
+                occurs[word]++
+                fs = append(fs, reporter.Finding{Check: "scanno", Severity: reporter.Warning,
+                    Line: n + 1, Column: col, Word: written,
+                    Message: fmt.Sprintf("scanno for %s", fix), Suggestion: fix})
+            }
+        }
+    }
+
+    var keys []string
+    for word := range found {
+        keys = append(keys, word)
+    }
+    sort.Strings(keys)
+    count := 0
+    for _, word := range keys {
+        s = append(s, fmt.Sprintf("%s (%d) -> %s", word, occurs[word],
+            suggest(word, sd[strings.ToLower(word)])))
+        s = append(s, found[word]...)
+        s = append(s, "")
+        count += occurs[word]
+    }
+    if count == 0 {
+        s = append(s, "  no scannos found in text.")
+    }
+
+    rs = append(rs, fmt.Sprintf("  scannos in text: %d occurrences of %d words", count, len(keys)))
+
+    // generate logscanno.txt from s, unless per-check logs are off
+    if fname != "" {
+        fileio.SaveText(s, fname, true, true)
+    }
+
+    // add to the report, without the log header
+    rpt.Add("scanno check", count, s[1:], marks)
+
+    // append to pptxt.log
+    *runlog = append(*runlog, rs...)
+    return fs
+}
@@ -4,19 +4,70 @@ import (
 	"strings"
 	"strconv"
 	"pptxt/wfreq"
+    "pptxt/dict"
     "pptxt/fileio"
+    "pptxt/reporter"
     "fmt"
     "sort"
     "time"
 )
 
-func lookup(wd []string, word string) bool {
-    ip := sort.SearchStrings(wd, word) // where it would insert
-    return (ip != len(wd) && wd[ip] == word) // true if we found it
+// language of the first dictionary containing the word, "" if none
+func lookup(wd []dict.Dictionary, word string) string {
+    for _, d := range wd {
+        if d.Contains(word) {
+            return d.Lang
+        }
+    }
+    return ""
+}
+
+// the dictionaries that apply inside a <lang xx> span: that language,
+// the main language of the book (the first dictionary) and goodwords.
+// outside any span (lang "") only the main language and goodwords apply
+func spanDicts(wd []dict.Dictionary, lang string) []dict.Dictionary {
+    var sd []dict.Dictionary
+    for n, d := range wd {
+        if n == 0 || d.Lang == dict.GoodWords || d.Lang == lang {
+            sd = append(sd, d)
+        }
+    }
+    return sd
+}
+
+// language approving form of word everywhere word appears, "" if none
+// without language spans (wl is nil) any dictionary may approve it
+// form is the word itself, its lowercase form or part of it if hyphenated
+func approve(wd []dict.Dictionary, wl map[string][]string, word string, form string) string {
+    if wl == nil {
+        return lookup(wd, form)
+    }
+    lang := ""
+    for _, span := range wl[word] {
+        if lang = lookup(spanDicts(wd, span), form); lang == "" {
+            return ""
+        }
+    }
+    return lang
+}
+
+// the language spans a word appears in, "" if only outside any span
+func spanLangs(spans []string) string {
+    var langs []string
+    for _, lang := range spans {
+        if lang != "" {
+            langs = append(langs, lang)
+        }
+    }
+    return strings.Join(langs, ",")
 }
 
 // spellcheck returns list of suspect words, list of ok words in text
-func Spellcheck(wb []string, wd []string, runlog *[]string, fname string) ([]string, []string) {
+// and a finding for each occurrence of a suspect word
+// a word is ok if any of the dictionaries in wd contains it
+// with langspans, words in <lang xx> spans are checked only against the
+// xx dictionary and the main language; other words only the main language
+func Spellcheck(wb []string, wd []dict.Dictionary, langspans bool, runlog *[]string, rpt *reporter.Report, fname string) ([]string, []string, []reporter.Finding) {
     var rs []string  // for logfile.txt
     rs = append(rs, "spellcheck")
 
@@ -24,14 +75,44 @@ func Spellcheck(wb []string, wd []string, runlog *[]string, fname string) ([]str
     var wb2 []map[string]struct{}
     okwordlist := make(map[string]int)  // cumulative words OK by successive tests
     var willdelete []string  // words to be deleted from wordlist
-    wlm, wb2 := wfreq.GetWordList(wb) // wordlist with word, frequency of word in map, wb2
+    var wlm map[string]int
+    var wl map[string][]string  // language spans each word appears in
+    if langspans {
+        var lwm map[string]map[string]int
+        wlm, wb2, lwm = wfreq.GetLangWordList(wb) // as GetWordList, with words by language span
+        wl = make(map[string][]string)
+        var langs []string
+        for lang := range lwm {
+            langs = append(langs, lang)
+        }
+        sort.Strings(langs)
+        for _, lang := range langs {
+            for word := range lwm[lang] {
+                wl[word] = append(wl[word], lang)
+            }
+            if lang == "" {
+                continue
+            }
+            rs = append(rs, fmt.Sprintf("  words in %s spans: %d words", lang, len(lwm[lang])))
+            selected := false
+            for _, d := range wd {
+                selected = selected || d.Lang == lang
+            }
+            if !selected {
+                rs = append(rs, fmt.Sprintf("  no %s dictionary selected for %s spans", lang, lang))
+            }
+        }
+    } else {
+        wlm, wb2 = wfreq.GetWordList(wb) // wordlist with word, frequency of word in map, wb2
+    }
     rs = append(rs, fmt.Sprintf("  unique words in text: %d words", len(wlm)))
 
+    bylang := make(map[string][]string)  // words approved by each dictionary
     for word, count := range wlm {
-        ip := sort.SearchStrings(wd, word) // where it would insert
-        if ip != len(wd) && wd[ip] == word { // true if we found it
+        if lang := approve(wd, wl, word, word); lang != "" {
             // ok by wordlist
             okwordlist[word] =  count // remember as good word
+            bylang[lang] = append(bylang[lang], word)
             willdelete = append(willdelete,word)
         }
     }
@@ -61,11 +142,11 @@ func Spellcheck(wb []string, wd []string, runlog *[]string, fname string) ([]str
 
     for word, count := range(wlm) {
         lcword := strings.ToLower(word)
-        ip := sort.SearchStrings(wd, lcword) // where it would insert
-        if ip != len(wd) && wd[ip] == lcword { // true if we found it
+        if lang := approve(wd, wl, word, lcword); lang != "" {
             // ok by lowercase
             lcwordlist[word] =  count // remember (uppercase versions) as good word
             okwordlist[word] =  count // remember as good word
+            bylang[lang] = append(bylang[lang], word)
             willdelete = append(willdelete,word)
         }
     }
@@ -96,7 +177,7 @@ func Spellcheck(wb []string, wd []string, runlog *[]string, fname string) ([]str
             // we have a hyphenated word
             allgood := true
             for _, hpart := range(t) {
-                if !lookup(wd, hpart) {
+                if approve(wd, wl, word, hpart) == "" {
                     allgood = false
                 }
             }
@@ -161,16 +242,28 @@ func Spellcheck(wb []string, wd []string, runlog *[]string, fname string) ([]str
     // show each word in context
     var s []string
     var sw []string
+    marks := make(map[int][]string)  // suspect words on each line
+    var fs []reporter.Finding  // each occurrence of a suspect word
     s = append(s, fmt.Sprintf("spellcheck report \nstarted: %s\n------------------------------",
         time.Now().Format(time.RFC850)))
     for word, _ := range(wlm) {
         sw = append(sw, word)  // simple slice of only the word
-        s = append(s, fmt.Sprintf("%s", word))  // word we will show in context
+        msg := "not in dictionary"
+        if langs := spanLangs(wl[word]); langs != "" {
+            s = append(s, fmt.Sprintf("%s [%s]", word, langs))  // suspect in a language span
+            msg = fmt.Sprintf("not in dictionary [%s]", langs)
+        } else {
+            s = append(s, fmt.Sprintf("%s", word))  // word we will show in context
+        }
         // show word in text
         for n, line := range(wb) {
             wordsonthisline := wb2[n] // a set of words on this line
             if _, ok := wordsonthisline[word]; ok {
-                s = append(s, fmt.Sprintf("  %6d: %s", n, line))
+                s = append(s, fmt.Sprintf("  %6d: %s", n+1, line))
+                marks[n] = append(marks[n], word)
+                w, col := reporter.Locate(line, word)  // as written, i.e. with ’
+                fs = append(fs, reporter.Finding{Check: "spellcheck", Severity: reporter.Warning,
+                    Line: n + 1, Column: col, Word: w, Message: msg})
             }
             // fmt.Printf("%+v\n", wordsonthisline)
             // s = append(s, fmt.Sprintf("  %d:  %s", n, line))
@@ -178,11 +271,32 @@ func Spellcheck(wb []string, wd []string, runlog *[]string, fname string) ([]str
         s = append(s, "")
     }
 
+    // show which language approved words not in the first dictionary
+    for n, d := range wd {
+        words := bylang[d.Lang]
+        if n == 0 || len(words) == 0 {
+            continue
+        }
+        sort.Strings(words)
+        s = append(s, fmt.Sprintf("approved by %s dictionary: %d words", d.Lang, len(words)))
+        s = append(s, fmt.Sprintf("  %s", strings.Join(words, " ")))
+        s = append(s, "")
+    }
+    rs = append(rs, "  approved by language:")
+    for _, d := range wd {
+        rs = append(rs, fmt.Sprintf("    %s: %d words", d.Lang, len(bylang[d.Lang])))
+    }
+
     rs = append(rs, fmt.Sprintf("  good words in text: %d words", len(okwordlist)))
     rs = append(rs, fmt.Sprintf("  suspect words in text: %d words", len(sw)))
 
-    // generate logspell.txt from s
-    fileio.SaveText(s, fname, true, true)
+    // generate logspell.txt from s, unless per-check logs are off
+    if fname != "" {
+        fileio.SaveText(s, fname, true, true)
+    }
+
+    // add to the report, without the log header
+    rpt.Add("spellcheck", len(sw), s[1:], marks)
 
     // append to pptxt.log
     *runlog = append(*runlog, rs...)
@@ -193,5 +307,5 @@ func Spellcheck(wb []string, wd []string, runlog *[]string, fname string) ([]str
     }
 
     // return sw: list of suspect words and ok: list of good words in text
-    return sw, ok
+    return sw, ok, fs
 }
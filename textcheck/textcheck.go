@@ -2,62 +2,109 @@ package textcheck
 
 import (
     "fmt"
-    "pptxt/fileio"
-    "time"
+    "pptxt/para"
+    "pptxt/reporter"
+    "pptxt/wfreq"
+    "regexp"
     "strings"
     "strconv"
     "sort"
+    "unicode"
+    "unicode/utf8"
 )
 
-var s []string  // to build the log specific to this test
-var rs []string  // to append to the overall runlog for all tests
+// a test as it runs: what it reports, the text it finds on each line
+// for the report, any text to find in one line of the report only, and
+// its findings. each run of a test has its own, so tests may run
+// repeatedly and concurrently
+type testRun struct {
+    s  []string
+    mk map[int][]string
+    lm map[int][]string
+    fs []reporter.Finding
+}
+
+func (tr *testRun) report(r string) {
+    tr.s = append(tr.s, r)
+}
+
+func (tr *testRun) mark(n int, text string) {
+    tr.mk[n] = append(tr.mk[n], text)
+}
 
-func report(r string) {
-    s = append(s, r)
+// mark text in the line last reported only, not in every line of the
+// report quoting the same line of the text
+func (tr *testRun) markReported(text string) {
+    i := len(tr.s) - 2  // in Lines, which lack the first line reported
+    tr.lm[i] = append(tr.lm[i], text)
 }
 
-func asteriskCheck(wb []string) {
-    report("asterisk check")
+// a finding on line n of wb: text at byte offset at in the line, or
+// the first match of text if at is -1. the text is also marked
+// Run fills in the check and severity
+func (tr *testRun) find(wb []string, n int, at int, text string, msg string) {
+    if text != "" {
+        tr.mark(n, text)
+    }
+    col := 0
+    if at >= 0 {
+        col = reporter.At(wb[n], at)
+    } else {
+        col = reporter.Column(wb[n], text)
+    }
+    tr.fs = append(tr.fs, reporter.Finding{Line: n + 1, Column: col, Word: text, Message: msg})
+}
+
+func (tr *testRun) asteriskCheck(wb []string) int {
+    tr.report("asterisk check")
     count := 0
     for n, line := range wb {
         if strings.Contains(line, "*") {
-            report(fmt.Sprintf("  %d: %s", n, line))
+            tr.report(fmt.Sprintf("  %d: %s", n+1, line))
+            tr.find(wb, n, strings.Index(line, "*"), "*", "asterisk")
             count += 1
         }
     }
     if count == 0 {
-        report("  no unexpected asterisks found in text.")
+        tr.report("  no unexpected asterisks found in text.")
     }
+    return count
 }
 
 // do not report adjacent spaces that start or end a line
-func adjacentSpaces(wb []string) {
-    report("adjacent spaces check")
+func (tr *testRun) adjacentSpaces(wb []string) int {
+    tr.report("adjacent spaces check")
     count := 0
     for n, line := range wb {
         if strings.Contains(strings.TrimSpace(line), "  ") {
-            report(fmt.Sprintf("  %d: %s", n, line))
+            tr.report(fmt.Sprintf("  %d: %s", n+1, line))
+            indent := len(line) - len(strings.TrimLeft(line, " "))
+            tr.find(wb, n, indent+strings.Index(line[indent:], "  "), "  ", "adjacent spaces")
             count += 1
         }
     }
     if count == 0 {
-        report("  no adjacent spaces found in text.")
+        tr.report("  no adjacent spaces found in text.")
     }
+    return count
 }
 
 // 
-func trailingSpaces(wb []string) {
-    report("trailing spaces check")
+func (tr *testRun) trailingSpaces(wb []string) int {
+    tr.report("trailing spaces check")
     count := 0
     for n, line := range wb {
         if strings.TrimSuffix(line, " ") != line {
-            report(fmt.Sprintf("  %d: %s", n, line))
+            tr.report(fmt.Sprintf("  %d: %s", n+1, line))
+            end := len(strings.TrimRight(line, " "))
+            tr.find(wb, n, end, line[end:], "trailing spaces")
             count += 1
         }
     }
     if count == 0 {
-        report("  no trailing spaces found in text.")
+        tr.report("  no trailing spaces found in text.")
     }
+    return count
 }
 
 type kv struct {
@@ -65,25 +112,33 @@ type kv struct {
     Value int
 }
 
-var m = map[rune]int{}  // a map for letter frequency counts
-
-// report infrequently-occuring characters (runes)
-// threshold set to fewer than 10 occurences
-// do not report numbers
-func letterChecks(wb []string) {
-    report("character checks")
-    count := 0
+// the number of times each character (rune) appears in wb
+func runeCounts(wb []string) map[rune]int {
+    m := make(map[rune]int)
     for _, line := range wb {
         for _, char := range(line) {  // this gets runes
             m[char] += 1 
         }
     }
+    return m
+}
+
+// report infrequently-occuring characters (runes)
+// threshold set to fewer than 10 occurences
+// do not report numbers
+func (tr *testRun) letterChecks(wb []string) int {
+    tr.report("character checks")
+    count := 0
+    m := runeCounts(wb)
     var ss []kv // slice of Key, Value pairs
     for k, v := range m {  // load it up
         ss = append(ss, kv{k, v})
     }
-    sort.Slice(ss, func(i, j int) bool {  // sort it based on Value
-        return ss[i].Value > ss[j].Value
+    sort.Slice(ss, func(i, j int) bool {  // sort it based on Value, then Key
+        if ss[i].Value != ss[j].Value {
+            return ss[i].Value > ss[j].Value
+        }
+        return ss[i].Key < ss[j].Key
     })
     for _, kv := range ss {
         reportme := false
@@ -95,15 +150,18 @@ func letterChecks(wb []string) {
         }
         if reportme {
             reportcount := 0
-            report(fmt.Sprintf("  %s", strconv.QuoteRune(kv.Key)))
+            tr.report(fmt.Sprintf("  %s", strconv.QuoteRune(kv.Key)))
             count += 1
             for n, line := range wb {
                 if strings.ContainsRune(line, kv.Key) {
+                    tr.find(wb, n, strings.IndexRune(line, kv.Key), string(kv.Key),
+                        fmt.Sprintf("unusual character %s", strconv.QuoteRune(kv.Key)))
                     if reportcount < 5 {
-                        report(fmt.Sprintf("    %d: %s", n, line))
+                        tr.report(fmt.Sprintf("    %d: %s", n+1, line))
+                        tr.markReported(string(kv.Key))  // not the other characters on the line
                     }
                     if reportcount == 5 {
-                        report(fmt.Sprintf("    ...more"))
+                        tr.report(fmt.Sprintf("    ...more"))
                     }
                     reportcount++
                 }
@@ -111,79 +169,1074 @@ func letterChecks(wb []string) {
         }
     }
     if count == 0 {
-        report("  no character checks reported.")
+        tr.report("  no character checks reported.")
     }       
+    return count
 }
 
-/*
-// special situations only report if they find something
-func spacingCheck(wb []string) {
+// report the lines in wb containing any of words, at most five of them
+// each word found is a finding with message msg, or only marked if
+// msg is ""
+func (tr *testRun) reportLines(wb []string, wb2 []map[string]struct{}, msg string, words ...string) {
+    reportcount := 0
+    for n, line := range wb {
+        found := false
+        for _, word := range words {
+            if _, ok := wb2[n][word]; ok {
+                found = true
+                if msg != "" {
+                    tr.find(wb, n, -1, word, msg)
+                } else if reportcount < 5 {
+                    tr.mark(n, word)
+                }
+            }
+        }
+        if !found {
+            continue
+        }
+        if reportcount < 5 {
+            tr.report(fmt.Sprintf("    %6d: %s", n+1, line))
+        }
+        if reportcount == 5 {
+            tr.report(fmt.Sprintf("    ...more"))
+        }
+        reportcount++
+    }
+}
+
+// true for a word with an upper case letter following a lower case one
+// i.e. tHe, mIxed, but not McDonald
+func internalCapital(word string) bool {
+    r := []rune(word)
+    for i := 1; i < len(r); i++ {
+        if unicode.IsLower(r[i-1]) && unicode.IsUpper(r[i]) {
+            if i == 2 && r[0] == 'M' && r[1] == 'c' {
+                continue
+            }
+            if i == 3 && string(r[:3]) == "Mac" {
+                continue
+            }
+            return true
+        }
+    }
+    return false
+}
+
+// true for a word of two or more letters all in upper case
+func allCaps(word string) bool {
+    letters := 0
+    for _, r := range word {
+        if unicode.IsLower(r) {
+            return false
+        }
+        if unicode.IsUpper(r) {
+            letters++
+        }
+    }
+    return letters > 1
+}
+
+// report words with abnormal or inconsistent capitalization:
+// internal capitals, all capitals also used in normal case and
+// capitalized words within a sentence also used in lower case
+func (tr *testRun) caseChecks(pb []string, pl []int, wb []string) int {
+    tr.report("case checks")
+    count := 0
+    wlm, wb2 := wfreq.GetWordList(wb) // wordlist with word, frequency of word in map, wb2
+    var words []string
+    for word := range wlm {
+        words = append(words, word)
+    }
+    sort.Strings(words)
+
+    for _, word := range words {
+        if internalCapital(word) {
+            tr.report(fmt.Sprintf("  %s (%d) internal capital", word, wlm[word]))
+            tr.reportLines(wb, wb2, "internal capital", word)
+            count++
+        }
+    }
+
+    for _, word := range words {
+        if !allCaps(word) {
+            continue
+        }
+        lc := strings.ToLower(word)
+        r := []rune(lc)
+        tc := string(unicode.ToUpper(r[0])) + string(r[1:])
+        if wlm[lc] == 0 && wlm[tc] == 0 {
+            continue
+        }
+        tr.report(fmt.Sprintf("  %s (%d) also %s (%d) %s (%d)", word, wlm[word], lc, wlm[lc], tc, wlm[tc]))
+        tr.reportLines(wb, wb2, fmt.Sprintf("all capitals, also %s (%d) %s (%d)", lc, wlm[lc], tc, wlm[tc]), word)
+        count++
+    }
+
+    // a capitalized word is within a sentence if the word before it
+    // ends with a letter or a comma
+    var re = regexp.MustCompile(`[\p{L}'’]+`)
+    within := make(map[string][]int)  // lines of each capitalized word within a sentence
+    for n, p := range pb {
+        for _, loc := range re.FindAllStringIndex(p, -1) {
+            word := p[loc[0]:loc[1]]
+            r := []rune(word)
+            if len(r) < 2 || !unicode.IsUpper(r[0]) || allCaps(word) {
+                continue
+            }
+            before := []rune(strings.TrimRight(p[:loc[0]], " "))
+            if len(before) == 0 {
+                continue
+            }
+            prev := before[len(before)-1]
+            if unicode.IsLetter(prev) || prev == ',' {
+                within[word] = append(within[word], para.Line(wb, pl[n], loc[0]))
+            }
+        }
+    }
+    var proper []string
+    for word := range within {
+        proper = append(proper, word)
+    }
+    sort.Strings(proper)
+    for _, word := range proper {
+        lc := strings.ToLower(word)
+        if wlm[lc] == 0 {
+            continue
+        }
+        tr.report(fmt.Sprintf("  %s (%d within a sentence) also %s (%d)",
+            word, len(within[word]), lc, wlm[lc]))
+        for i, n := range within[word] {
+            tr.find(wb, n, -1, word, fmt.Sprintf("capitalized within a sentence, also %s (%d)", lc, wlm[lc]))
+            if i < 5 {
+                tr.report(fmt.Sprintf("    %6d: %s", n+1, wb[n]))
+            }
+            if i == 5 {
+                tr.report(fmt.Sprintf("    ...more"))
+            }
+        }
+        tr.report(fmt.Sprintf("    %s", lc))
+        tr.reportLines(wb, wb2, "", lc)
+        count++
+    }
+
+    if count == 0 {
+        tr.report("  no case checks reported.")
+    }
+    return count
+}
+
+// words that may legitimately be repeated, i.e. "had had", "that that"
+var repeatOK = map[string]bool{
+    "had":  true,
+    "that": true,
+}
+
+// report immediately repeated words, i.e. "the the", ignoring case
+// checked by paragraph to find those split across a line break
+func (tr *testRun) repeatedWords(pb []string, pl []int, wb []string) int {
+    tr.report("repeated words check")
+    count := 0
+    var re = regexp.MustCompile(`[\p{L}\p{N}'’]+`)
+    for n, p := range pb {
+        prev := ""  // previous word in paragraph, lower case
+        prevloc := []int{0, 0}
+        for _, loc := range re.FindAllStringIndex(p, -1) {
+            word := strings.ToLower(p[loc[0]:loc[1]])
+            if word == prev && !repeatOK[word] && strings.TrimSpace(p[prevloc[1]:loc[0]]) == "" {
+                first := para.Line(wb, pl[n], prevloc[0])
+                second := para.Line(wb, pl[n], loc[0])
+                tr.report(fmt.Sprintf("  \"%s\"", p[prevloc[0]:loc[1]]))
+                tr.report(fmt.Sprintf("    %6d: %s", first+1, wb[first]))
+                if second != first {
+                    tr.report(fmt.Sprintf("    %6d: %s", second+1, wb[second]))
+                    tr.find(wb, first, -1, p[prevloc[0]:prevloc[1]], "repeated word, across a line break")
+                    tr.mark(second, p[loc[0]:loc[1]])
+                } else {
+                    tr.find(wb, first, -1, p[prevloc[0]:loc[1]], "repeated word")
+                }
+                count++
+            }
+            prev = word
+            prevloc = loc
+        }
+    }
+    if count == 0 {
+        tr.report("  no repeated words found in text.")
+    }
+    return count
+}
+
+// the first problem with the curly quotes in a paragraph, "" if none
+// ’ between letters is an apostrophe, as is ’ at the end of a word
+// unless it closes an open single quote. ‘ after a letter is ignored.
+// a ’ ending a word that closes a quote is provisional: if a later ’
+// has nothing to close, the earlier one was an apostrophe, as in
+// ‘the boys’ hats,’ and the later one closes the quote instead.
+// a paragraph may leave its quotes open if the next paragraph
+// continues the quote by starting with the same quote mark
+func quoteBalance(p string, next string) string {
+    var stack []rune  // open quotes
+    var provisional []int  // open quotes left after each ’ ending a word closed one
+    r := []rune(p)
+    for i, c := range r {
+        before := i > 0 && unicode.IsLetter(r[i-1])
+        after := i < len(r)-1 && unicode.IsLetter(r[i+1])
+        switch c {
+        case '“':
+            if len(stack) > 0 && stack[len(stack)-1] == '“' {
+                return "“ inside open “"
+            }
+            stack = append(stack, c)
+        case '”':
+            if len(stack) == 0 {
+                return "” without opening “"
+            }
+            if stack[len(stack)-1] != '“' {
+                return "” closes open ‘"
+            }
+            stack = stack[:len(stack)-1]
+        case '‘':
+            if before {
+                continue
+            }
+            if len(stack) > 0 && stack[len(stack)-1] == '‘' {
+                return "‘ inside open ‘"
+            }
+            stack = append(stack, c)
+        case '’':
+            if before && after {
+                continue  // apostrophe within a word
+            }
+            if len(stack) > 0 && stack[len(stack)-1] == '‘' && !after {
+                stack = stack[:len(stack)-1]
+                if before {
+                    provisional = append(provisional, len(stack))
+                }
+                continue
+            }
+            if before || after {
+                continue  // apostrophe ending or starting a word
+            }
+            if i > 0 && unicode.IsSpace(r[i-1]) {
+                continue  // standing alone, i.e. a ’ ’em
+            }
+            if n := len(provisional); n > 0 && provisional[n-1] == len(stack) {
+                provisional = provisional[:n-1]  // closes the quote the earlier ’ did not
+                continue
+            }
+            return "’ without opening ‘"
+        }
+    }
+    if len(stack) == 0 {
+        return ""
+    }
+    if strings.HasPrefix(next, string(stack[0])) {
+        return ""  // quote continues into the next paragraph
+    }
+    return fmt.Sprintf("unclosed %s", string(stack[len(stack)-1]))
+}
+
+// report paragraphs with unbalanced or mis-nested curly quotes
+func (tr *testRun) quoteChecks(pb []string, pl []int, wb []string) int {
+    tr.report("curly quote balance check")
     count := 0
-    s := ""
-    report("spacing check")
-    
+    for n, p := range pb {
+        next := ""
+        if n < len(pb)-1 {
+            next = pb[n+1]
+        }
+        if problem := quoteBalance(p, next); problem != "" {
+            tr.report(fmt.Sprintf("  %6d: %s", pl[n]+1, wb[pl[n]]))
+            tr.report(fmt.Sprintf("          %s", problem))
+            tr.find(wb, pl[n], -1, "", problem)
+            count++
+        }
+    }
+    if count == 0 {
+        tr.report("  no unbalanced quotes found in text.")
+    }
+    return count
+}
+
+// compact form of the blank lines before each block of a chapter
+// runs of paragraph spacing are counted, i.e. "4 2 1(12) 2 1(30)"
+func spacingSummary(pattern []int) string {
+    var t []string
+    for i := 0; i < len(pattern); i++ {
+        run := 1
+        for pattern[i] == 1 && i+run < len(pattern) && pattern[i+run] == 1 {
+            run++
+        }
+        if run > 1 {
+            t = append(t, fmt.Sprintf("1(%d)", run))
+            i += run - 1
+        } else {
+            t = append(t, strconv.Itoa(pattern[i]))
+        }
+    }
+    return strings.Join(t, " ")
+}
+
+// the blank lines before each block of text should be
+// 4 before a chapter, 2 before a section, 1 between paragraphs
+// report lines preceded by any other number of blank lines
+// and summarize the spacing of each chapter
+func (tr *testRun) spacingCheck(wb []string) int {
+    count := 0
+    tr.report("spacing check")
+
+    var summary []string
+    var pattern []int  // blank lines before each block in this chapter
+    chapter := 0  // line where this chapter starts
     consec := 0  // consecutive blank lines
-    for _,line := range wb {
+    started := false  // blank lines at start of file are not counted
+    for n, line := range wb {
         if line == "" {
             consec++
-        } else { // a non-blank line
+            continue
+        }
+        // a non-blank line
+        if started && consec > 0 {
             if consec >= 4 {  // start of a new chapter
-                fmt.Println(s)
-                s = "4"
-            } else {
-                if consec > 0 {
-                    s = fmt.Sprintf("%s%d", s, consec)
+                if len(pattern) > 0 {
+                    summary = append(summary, fmt.Sprintf("  %6d: %s", chapter+1, spacingSummary(pattern)))
                 }
+                chapter = n
+                pattern = nil
             }
-            consec = 0
+            if consec == 3 || consec > 4 {
+                tr.report(fmt.Sprintf("  %6d: %d blank lines before: %s", n+1, consec, line))
+                tr.find(wb, n, -1, "", fmt.Sprintf("%d blank lines before", consec))
+                count++
+            }
+            pattern = append(pattern, consec)
+        }
+        started = true
+        consec = 0
+    }
+    if len(pattern) > 0 {
+        summary = append(summary, fmt.Sprintf("  %6d: %s", chapter+1, spacingSummary(pattern)))
+    }
+
+    if count == 0 {
+        tr.report("  no spacing errors reported.")
+    }
+    tr.report("  spacing summary, by chapter")
+    for _, line := range summary {
+        tr.report(line)
+    }
+    return count
+}
+
+// report lines longer than long runes, with a summary of the
+// longest lines in the text
+func (tr *testRun) longLines(wb []string, long int) int {
+    tr.report(fmt.Sprintf("long line check (over %d characters)", long))
+    count := 0
+    type ll struct {
+        n      int
+        length int
+    }
+    var lengths []ll
+    for n, line := range wb {
+        length := utf8.RuneCountInString(line)
+        lengths = append(lengths, ll{n, length})
+        if length > long {
+            tr.report(fmt.Sprintf("  %6d: (%d) %s", n+1, length, line))
+            at := len(string([]rune(line)[:long]))
+            tr.find(wb, n, at, line[at:], fmt.Sprintf("line longer than %d characters (%d)", long, length))
+            count++
+        }
+    }
+    if count == 0 {
+        tr.report("  no long lines found in text.")
+    }
+    sort.SliceStable(lengths, func(i, j int) bool {
+        return lengths[i].length > lengths[j].length
+    })
+    tr.report("  longest lines")
+    for i, l := range lengths {
+        if i == 5 {
+            break
         }
+        tr.report(fmt.Sprintf("  %6d: (%d) %s", l.n+1, l.length, wb[l.n]))
     }
+    return count
+}
+
+// the last line of the paragraph starting on line start
+func lastLine(wb []string, start int) int {
+    end := start
+    for end < len(wb)-1 && wb[end+1] != "" {
+        end++
+    }
+    return end
+}
 
+// true if the paragraph on lines start to end is verse, as -rewrap
+// also decides
+func verse(wb []string, start int, end int, short int) bool {
+    return para.Verse(wb[start:end+1], short)
+}
+
+// report lines shorter than short runes in the middle of a paragraph,
+// which suggest broken wrapping. the last line of each paragraph is
+// expected to be short. indented blocks and blocks of mostly short
+// lines (poetry) are not checked
+func (tr *testRun) shortLines(pl []int, wb []string, short int) int {
+    tr.report(fmt.Sprintf("short line check (under %d characters)", short))
+    count := 0
+    for _, start := range pl {
+        end := lastLine(wb, start)
+        if verse(wb, start, end, short) {
+            continue
+        }
+        var suspects []int
+        for n := start; n < end; n++ {
+            if utf8.RuneCountInString(wb[n]) < short {
+                suspects = append(suspects, n)
+            }
+        }
+        for _, n := range suspects {
+            tr.report(fmt.Sprintf("  %6d: (%d) %s", n+1, utf8.RuneCountInString(wb[n]), wb[n]))
+            tr.find(wb, n, -1, "", fmt.Sprintf("line shorter than %d characters (%d) within a paragraph",
+                short, utf8.RuneCountInString(wb[n])))
+            count++
+        }
+    }
     if count == 0 {
-        report("  no spacing errors reported.")
-    }       
+        tr.report("  no short lines found in text.")
+    }
+    return count
+}
+
+// the style of a run of dashes at line[start:end]
+func dashStyle(line string, start int, end int) string {
+    run := line[start:end]
+    spaceBefore := start > 0 && line[start-1] == ' '
+    spaceAfter := end < len(line) && line[end] == ' '
+    switch {
+    case strings.Trim(run, "-") == "":
+        if len(run) > 2 {
+            return "hyphen rule"  // i.e. a page separator or a drawn line
+        }
+        if len(run) == 2 {
+            return "double hyphen"
+        }
+        if spaceBefore && spaceAfter {
+            return "spaced hyphen"
+        }
+        return "hyphen"
+    case strings.Trim(run, "–") == "":
+        return "en-dash"
+    case strings.Trim(run, "—") == "":
+        if utf8.RuneCountInString(run) > 1 {
+            return "long em-dash"
+        }
+        if start == 0 {
+            return "em-dash at line start"
+        }
+        if end == len(line) {
+            return "em-dash at line end"
+        }
+        if spaceBefore || spaceAfter {
+            return "spaced em-dash"
+        }
+        return "em-dash"
+    }
+    return "mixed dashes"
+}
+
+// styles that should not be mixed for the same purpose. hyphen rules
+// are not em-dashes
+var emdashStyles = []string{"em-dash", "spaced em-dash", "double hyphen", "spaced hyphen"}
+
+// classify every dash in the text and report the counts of each style
+// if the em-dash is written more than one way, the lines of all but
+// the most common way are reported, as are em-dashes starting or
+// ending a line and mixed runs of dashes
+func (tr *testRun) dashChecks(wb []string) int {
+    tr.report("dash check")
+    count := 0
+    var re = regexp.MustCompile(`[-–—]+`)
+    counts := make(map[string]int)
+    lines := make(map[string][]int)
+    runs := make(map[string]map[int][][]int)  // dashes of each style on each line
+    for n, line := range wb {
+        for _, loc := range re.FindAllStringIndex(line, -1) {
+            style := dashStyle(line, loc[0], loc[1])
+            counts[style]++
+            if len(lines[style]) == 0 || lines[style][len(lines[style])-1] != n {
+                lines[style] = append(lines[style], n)
+            }
+            if runs[style] == nil {
+                runs[style] = make(map[int][][]int)
+            }
+            runs[style][n] = append(runs[style][n], loc)
+        }
+    }
+    var styles []string
+    for style := range counts {
+        styles = append(styles, style)
+    }
+    sort.Strings(styles)
+    for _, style := range styles {
+        tr.report(fmt.Sprintf("  %s: %d", style, counts[style]))
+    }
+
+    var suspect []string  // styles to show lines for
+    major := ""
+    used := 0
+    for _, style := range emdashStyles {
+        if counts[style] > 0 {
+            used++
+            if major == "" || counts[style] > counts[major] {
+                major = style
+            }
+        }
+    }
+    if used > 1 {
+        tr.report(fmt.Sprintf("  em-dashes written %d ways, most often as %s", used, major))
+        for _, style := range emdashStyles {
+            if counts[style] > 0 && style != major {
+                suspect = append(suspect, style)
+            }
+        }
+    }
+    suspect = append(suspect, "em-dash at line start", "em-dash at line end", "mixed dashes")
+    for _, style := range suspect {
+        if len(lines[style]) == 0 {
+            continue
+        }
+        tr.report(fmt.Sprintf("  %s", style))
+        for _, n := range lines[style] {
+            tr.report(fmt.Sprintf("    %6d: %s", n+1, wb[n]))
+            for _, loc := range runs[style][n] {
+                tr.find(wb, n, loc[0], wb[n][loc[0]:loc[1]], style)
+            }
+            count++
+        }
+    }
+    if count == 0 {
+        tr.report("  no inconsistent dashes found in text.")
+    }
+    return count
+}
+
+// the style of an ellipsis
+func ellipsisStyle(e string) string {
+    switch {
+    case e == "…":
+        return "…"
+    case strings.HasPrefix(e, "…"):
+        return "…."
+    case strings.Contains(e, " "):
+        if strings.Count(e, ".") == 3 {
+            return ". . ."
+        }
+        return ". . . ."
+    case len(e) == 3:
+        return "..."
+    case len(e) == 4:
+        return "...."
+    }
+    return "more than four dots"
+}
+
+// report the ellipsis styles used in the text, the lines of all
+// but the most common style and any ellipsis spaced as if it
+// belongs to the word after it, i.e. "word ...word" or "word...word"
+func (tr *testRun) ellipsisChecks(wb []string) int {
+    tr.report("ellipsis check")
+    count := 0
+    var re = regexp.MustCompile(`\.(?: \.){2,}|\.{3,}|…\.?`)
+    counts := make(map[string]int)
+    lines := make(map[string][]int)
+    runs := make(map[string]map[int][][]int)  // ellipses of each style on each line
+    var spacing []int  // lines with incorrectly spaced ellipses
+    spaced := make(map[int][][]int)  // the incorrectly spaced ellipses
+    for n, line := range wb {
+        for _, loc := range re.FindAllStringIndex(line, -1) {
+            style := ellipsisStyle(line[loc[0]:loc[1]])
+            counts[style]++
+            if len(lines[style]) == 0 || lines[style][len(lines[style])-1] != n {
+                lines[style] = append(lines[style], n)
+            }
+            if runs[style] == nil {
+                runs[style] = make(map[int][][]int)
+            }
+            runs[style][n] = append(runs[style][n], loc)
+            before, _ := utf8.DecodeLastRuneInString(line[:loc[0]])
+            after, _ := utf8.DecodeRuneInString(line[loc[1]:])
+            if loc[0] > 0 && (before == ' ' || unicode.IsLetter(before)) && unicode.IsLetter(after) {
+                if len(spacing) == 0 || spacing[len(spacing)-1] != n {
+                    spacing = append(spacing, n)
+                }
+                spaced[n] = append(spaced[n], loc)
+            }
+        }
+    }
+    var styles []string
+    for style := range counts {
+        styles = append(styles, style)
+    }
+    sort.Strings(styles)
+    sort.SliceStable(styles, func(i, j int) bool {
+        return counts[styles[i]] > counts[styles[j]]
+    })
+    for _, style := range styles {
+        tr.report(fmt.Sprintf("  \"%s\": %d", style, counts[style]))
+    }
+    if len(styles) > 1 {
+        for _, style := range styles[1:] {  // the minority styles
+            tr.report(fmt.Sprintf("  \"%s\"", style))
+            for _, n := range lines[style] {
+                tr.report(fmt.Sprintf("    %6d: %s", n+1, wb[n]))
+                for _, loc := range runs[style][n] {
+                    tr.find(wb, n, loc[0], wb[n][loc[0]:loc[1]],
+                        fmt.Sprintf("ellipsis \"%s\", most often \"%s\"", style, styles[0]))
+                }
+                count++
+            }
+        }
+    }
+    if len(spacing) > 0 {
+        tr.report("  incorrectly spaced")
+        for _, n := range spacing {
+            tr.report(fmt.Sprintf("    %6d: %s", n+1, wb[n]))
+            for _, loc := range spaced[n] {
+                tr.find(wb, n, loc[0], wb[n][loc[0]:loc[1]], "incorrectly spaced ellipsis")
+            }
+            count++
+        }
+    }
+    if count == 0 {
+        tr.report("  no inconsistent ellipses found in text.")
+    }
+    return count
+}
+
+// a punctuation spacing rule. submatch 1 of re is the offending text,
+// ending with the punctuation mark if there is one. except, if not nil,
+// excuses a match given the byte offset of that last character
+type punctRule struct {
+    name   string
+    re     *regexp.Regexp
+    except func(line string, at int) bool
+}
+
+// the word containing byte offset at, delimited by spaces
+func wordAt(line string, at int) string {
+    start := strings.LastIndex(line[:at], " ") + 1
+    end := strings.Index(line[at:], " ")
+    if end < 0 {
+        return line[start:]
+    }
+    return line[start : at+end]
+}
+
+// abbreviations with a period inside them, i.e. e.g., i.e., U.S.A.,
+// are single letters separated by periods. web addresses are allowed
+func abbreviation(line string, at int) bool {
+    word := strings.Trim(wordAt(line, at), "“”‘’\"'(),;:!?")
+    if strings.HasPrefix(word, "www.") || strings.Contains(word, "://") {
+        return true
+    }
+    for _, part := range strings.Split(strings.TrimSuffix(word, "."), ".") {
+        if utf8.RuneCountInString(part) != 1 {
+            return false
+        }
+    }
+    return true
+}
+
+// decimals and times, i.e. 3.30, 1,000, 10:15, have digits both sides
+func number(line string, at int) bool {
+    before, _ := utf8.DecodeLastRuneInString(line[:at])
+    after, _ := utf8.DecodeRuneInString(line[at+1:])
+    return unicode.IsDigit(before) && unicode.IsDigit(after)
+}
+
+// a period that starts an ellipsis, i.e. "..." or ". . .", is left to
+// the ellipsis check
+func ellipsis(line string, at int) bool {
+    return strings.HasPrefix(line[at:], "..") || strings.HasPrefix(line[at:], ". .")
+}
+
+var punctRules = []punctRule{
+    {"space before punctuation", regexp.MustCompile(`\S( [,;:!?])`), nil},
+    {"space before period", regexp.MustCompile(`\p{L}( \.)`), ellipsis},
+    {"missing space after punctuation", regexp.MustCompile(`[\p{L}\p{N}]([,;:!?.])[\p{L}\p{N}]`),
+        func(line string, at int) bool {
+            return number(line, at) || abbreviation(line, at)
+        }},
+    {"space after opening bracket", regexp.MustCompile(`[(\[{]( )`), nil},
+    {"space before closing bracket", regexp.MustCompile(`\S( )[)\]}]`), nil},
+    {"space after opening quote", regexp.MustCompile(`(?:^|\s)“( )`), nil},
+    {"space before closing quote", regexp.MustCompile(`\S( )”(?:\s|$)`), nil},
+}
+
+// the text to highlight for a punctuation spacing error: the submatch
+// with the mark after it, the bracket or quote before a lone space, or
+// the whole word if it runs two words together
+func punctMark(line string, loc []int) string {
+    t := strings.TrimRight(line[loc[2]:loc[1]], " \t")
+    if strings.TrimSpace(t) == "" {
+        return line[loc[0]:loc[3]]
+    }
+    if strings.IndexFunc(t, unicode.IsLetter) >= 0 || strings.IndexFunc(t, unicode.IsDigit) >= 0 {
+        return wordAt(line, loc[2])
+    }
+    return t
+}
+
+// report punctuation spacing errors, i.e. "word ,word", "end.Next",
+// "( text )", with a caret under the offending column
+func (tr *testRun) punctuationSpacing(wb []string) int {
+    tr.report("punctuation spacing check")
+    count := 0
+    for _, rule := range punctRules {
+        reported := false
+        for n, line := range wb {
+            for _, loc := range rule.re.FindAllStringSubmatchIndex(line, -1) {
+                at := loc[2]  // start of submatch 1
+                if rule.except != nil && rule.except(line, loc[3]-1) {
+                    continue
+                }
+                if !reported {
+                    tr.report(fmt.Sprintf("  %s", rule.name))
+                    reported = true
+                }
+                tr.report(fmt.Sprintf("    %6d: %s", n+1, line))
+                tr.report(fmt.Sprintf("    %6s  %s^", "", strings.Repeat(" ", utf8.RuneCountInString(line[:at]))))
+                tr.find(wb, n, at, punctMark(line, loc), rule.name)
+                count++
+            }
+        }
+    }
+    if count == 0 {
+        tr.report("  no punctuation spacing errors found in text.")
+    }
+    return count
+}
+
+// a class of leftover markup. id is used to allow the class
+type markupClass struct {
+    id   string
+    name string
+    re   *regexp.Regexp
+}
+
+// in order; markup matching an earlier class is not reported again
+// in a later class, i.e. <tb> is a thought break, not an HTML tag
+var markupClasses = []markupClass{
+    {"thoughtbreak", "thought break", regexp.MustCompile(`^\s*\*(\s+\*){2,}\s*$|<tb>`)},
+    {"lang", "language markup", regexp.MustCompile(`</?lang\b[^>]*>`)},
+    {"html", "HTML tag", regexp.MustCompile(`</?[A-Za-z][A-Za-z0-9]*(\s[^<>]*)?/?>`)},
+    {"note", "proofer note", regexp.MustCompile(`\[\*\*`)},
+    {"footnote", "unconverted footnote", regexp.MustCompile(`\[Footnote`)},
+    {"sidenote", "unconverted sidenote", regexp.MustCompile(`\[Sidenote`)},
+    {"illustration", "unconverted illustration", regexp.MustCompile(`\[Illustration`)},
+    {"rewrap", "rewrap marker", regexp.MustCompile(`^\s*(/\*|\*/|/#|#/)\s*$`)},
+}
+
+// report markup left over from the proofing rounds, by class.
+// classes in allow are not reported, i.e. illustrations kept on purpose
+func (tr *testRun) markupChecks(wb []string, allow []string) int {
+    tr.report("markup check")
+    count := 0
+    allowed := make(map[string]bool)
+    for _, id := range allow {
+        allowed[id] = true
+    }
+    lines := make(map[string][]int)
+    found := make(map[string]map[int][][]int)  // markup of each class on each line
+    for n, line := range wb {
+        for i, mc := range markupClasses {
+            for _, loc := range mc.re.FindAllStringIndex(line, -1) {
+                m := line[loc[0]:loc[1]]
+                earlier := false
+                for _, prev := range markupClasses[:i] {
+                    earlier = earlier || prev.re.MatchString(m)
+                }
+                if !earlier {
+                    if len(lines[mc.id]) == 0 || lines[mc.id][len(lines[mc.id])-1] != n {
+                        lines[mc.id] = append(lines[mc.id], n)
+                    }
+                    if found[mc.id] == nil {
+                        found[mc.id] = make(map[int][][]int)
+                    }
+                    found[mc.id][n] = append(found[mc.id][n], loc)
+                }
+            }
+        }
+    }
+    for _, mc := range markupClasses {
+        if len(lines[mc.id]) == 0 {
+            continue
+        }
+        if allowed[mc.id] {
+            tr.report(fmt.Sprintf("  %s: %d allowed", mc.name, len(lines[mc.id])))
+            continue
+        }
+        tr.report(fmt.Sprintf("  %s (-allowmarkup %s)", mc.name, mc.id))
+        for _, n := range lines[mc.id] {
+            tr.report(fmt.Sprintf("    %6d: %s", n+1, wb[n]))
+            for _, loc := range found[mc.id][n] {
+                tr.find(wb, n, loc[0], wb[n][loc[0]:loc[1]], mc.name)
+            }
+            count++
+        }
+    }
+    if count == 0 {
+        tr.report("  no unconverted markup found in text.")
+    }
+    return count
+}
+
+// report paragraphs that do not end in terminal punctuation,
+// which finds missing periods and paragraphs split by mistake.
+// headings (a short line after two or more blank lines), poetry and short lines
+// all in capitals are not checked
+func (tr *testRun) paragraphEndings(pb []string, pl []int, wb []string, short int) int {
+    tr.report("paragraph ending check")
+    count := 0
+    for n, p := range pb {
+        start := pl[n]
+        end := lastLine(wb, start)
+        after := start < 2 || (wb[start-1] == "" && wb[start-2] == "")  // two or more blank lines
+        if after && start == end && utf8.RuneCountInString(wb[start]) < short {
+            continue  // a heading
+        }
+        if verse(wb, start, end, short) {
+            continue
+        }
+        if start == end && allCaps(wb[start]) && utf8.RuneCountInString(wb[start]) < short {
+            continue
+        }
+        last, _ := utf8.DecodeLastRuneInString(strings.TrimRight(p, " "))
+        if strings.ContainsRune(".!?”’—:", last) {
+            continue
+        }
+        tr.report(fmt.Sprintf("  %6d: %s", end+1, wb[end]))
+        tr.find(wb, end, -1, "", "paragraph does not end in punctuation")
+        count++
+    }
+    if count == 0 {
+        tr.report("  no paragraph ending problems found in text.")
+    }
+    return count
+}
+
+// report paragraphs starting with a lower case letter, often one
+// paragraph split by mistake. each is shown after the last line of the
+// paragraph before it to see if they should be joined.
+// indented paragraphs and poetry are not checked
+func (tr *testRun) paragraphStarts(pb []string, pl []int, wb []string, short int) int {
+    tr.report("paragraph start check")
+    count := 0
+    for n, p := range pb {
+        if n == 0 || verse(wb, pl[n], lastLine(wb, pl[n]), short) {
+            continue
+        }
+        first := []rune(strings.TrimLeft(p, " “‘\"'(_"))
+        if len(first) == 0 || !unicode.IsLower(first[0]) {
+            continue
+        }
+        prev := lastLine(wb, pl[n-1])
+        tr.report(fmt.Sprintf("  %6d: %s", prev+1, wb[prev]))
+        tr.report(fmt.Sprintf("  %6d: %s", pl[n]+1, wb[pl[n]]))
+        tr.report("")
+        tr.find(wb, pl[n], -1, "", "paragraph starts in lower case")
+        count++
+    }
+    if count == 0 {
+        tr.report("  no paragraphs starting in lower case found in text.")
+    }
+    return count
+}
+
+// report up to five of the lines, then how many more
+// each line is a finding with message msg
+func (tr *testRun) reportSome(wb []string, lines []int, msg string) {
+    for i, n := range lines {
+        tr.find(wb, n, -1, strings.TrimSpace(wb[n]), msg)
+        if i < 5 {
+            tr.report(fmt.Sprintf("    %6d: %s", n+1, wb[n]))
+        }
+        if i == 5 {
+            tr.report(fmt.Sprintf("    ...%d more", len(lines)-5))
+        }
+    }
+}
+
+// a well-formed lower case roman numeral, i.e. xiv but not did or
+// civil. mix is also a word, so is not taken for 1009
+var reRoman = regexp.MustCompile(`^m{0,3}(cm|cd|d?c{0,3})(xc|xl|l?x{0,3})(ix|iv|v?i{0,3})$`)
+
+// a line holding only a number, which may be in brackets
+var reNum = regexp.MustCompile(`^\s*[\[(]?(\d+|[ivxlcdm]+)[\])]?\s*$`)
+
+// true if line holds only a page number, arabic or roman
+func pageNumber(line string) bool {
+    m := reNum.FindStringSubmatch(line)
+    if m == nil {
+        return false
+    }
+    if m[1][0] >= '0' && m[1][0] <= '9' {
+        return true
+    }
+    return reRoman.MatchString(m[1]) && m[1] != "mix"
+}
+
+// report what is left of the printed page: page separators,
+// i.e. -----File: 001.png---, lines holding only a page number and
+// running headers, short lines repeated ten or more times
+func (tr *testRun) pageRemnants(wb []string) int {
+    tr.report("page remnants check")
+    count := 0
+    var reSep = regexp.MustCompile(`^-+File: ?\S+`)
+    var reText = regexp.MustCompile(`[\p{L}\p{N}]`)
+    var separators, numbers []int
+    repeated := make(map[string][]int)  // lines of each short line of text
+    for n, line := range wb {
+        switch {
+        case reSep.MatchString(line):
+            separators = append(separators, n)
+        case pageNumber(line):
+            numbers = append(numbers, n)
+        case reText.MatchString(line) && utf8.RuneCountInString(strings.TrimSpace(line)) <= 40:
+            t := strings.TrimSpace(line)
+            repeated[t] = append(repeated[t], n)
+        }
+    }
+    if len(separators) > 0 {
+        tr.report(fmt.Sprintf("  page separators (%d)", len(separators)))
+        tr.reportSome(wb, separators, "page separator")
+        count += len(separators)
+    }
+    if len(numbers) > 0 {
+        tr.report(fmt.Sprintf("  page numbers (%d)", len(numbers)))
+        tr.reportSome(wb, numbers, "page number")
+        count += len(numbers)
+    }
+    var headers []string
+    for t, lines := range repeated {
+        if len(lines) >= 10 {
+            headers = append(headers, t)
+        }
+    }
+    sort.Strings(headers)
+    for _, t := range headers {
+        tr.report(fmt.Sprintf("  running header \"%s\" (%d)", t, len(repeated[t])))
+        tr.reportSome(wb, repeated[t], "running header")
+        count += len(repeated[t])
+    }
+    if count == 0 {
+        tr.report("  no page remnants found in text.")
+    }
+    return count
 }
-*/
 
 // special situations only report if they find something
-func specialSituations(wb []string) {
+func (tr *testRun) specialSituations(wb []string) int {
     count := 0
-    report("special situations checks")
-    
+    tr.report("special situations checks")
+    m := runeCounts(wb)
+
     if m['\''] > 0 && ( m['‘'] > 0 || m['’'] > 0 ) {
-        report("  both straight and curly single quotes found in text")
+        tr.report("  both straight and curly single quotes found in text")
+        tr.fs = append(tr.fs, reporter.Finding{Message: "both straight and curly single quotes found in text"})
         count++
     }
     if m['"'] > 0 && ( m['“'] > 0 || m['”'] > 0 ) {
-        report("  both straight and curly double quotes found in text")
+        tr.report("  both straight and curly double quotes found in text")
+        tr.fs = append(tr.fs, reporter.Finding{Message: "both straight and curly double quotes found in text"})
         count++
     }
 
     if count == 0 {
-        report("  no special situations checks reported.")
+        tr.report("  no special situations checks reported.")
     }       
+    return count
 }
 
-// text checks
-// a series of tests either on the working buffer (line at a time)
-// or the paragraph buffer (paragraph at a time)
-func Textcheck(pb []string, wb []string, runlog *[]string, fname string) {
-    rs = append(rs, "Text checks")
-    s = append(s, fmt.Sprintf("text check report \nstarted: %s\n------------------------------",
-        time.Now().Format(time.RFC850)))
+// the text the tests check and their settings
+type Text struct {
+    Pb []string  // paragraph buffer
+    Pl []int  // line in Wb where each paragraph starts
+    Wb []string  // working buffer
+    Long  int  // report lines longer than this
+    Short int  // report lines within a paragraph shorter than this
+    AllowMarkup []string  // markup classes not to report
+}
 
-    rs = append(rs, "  added to runlog by Textcheck")
+// the results of a test, a section of the report
+type Result struct {
+    Name      string  // the first line the test reports, i.e. "asterisk check"
+    Count     int
+    Lines     []string
+    Marks     map[int][]string
+    LineMarks map[int][]string  // by index in Lines, in place of Marks
+    Findings  []reporter.Finding
+}
 
-    asteriskCheck(wb)
-    adjacentSpaces(wb)
-    trailingSpaces(wb)
-    letterChecks(wb)
-    // spacingCheck(wb)
-    specialSituations(wb)
+// a text check. each test either works on the working buffer (line
+// at a time) or the paragraph buffer (paragraph at a time)
+type Test struct {
+    ID          string  // i.e. "asterisk"
+    Description string
+    Severity    string  // of its findings
+    run         func(tr *testRun, x Text) int
+}
 
-    // generate logtext.txt from s
-    fileio.SaveText(s, fname, true, true)
+// text checks, in the order they run
+var Tests = []Test{
+    {"asterisk", "lines with asterisks", reporter.Warning,
+        func(tr *testRun, x Text) int { return tr.asteriskCheck(x.Wb) }},
+    {"adjacent-spaces", "adjacent spaces within a line", reporter.Warning,
+        func(tr *testRun, x Text) int { return tr.adjacentSpaces(x.Wb) }},
+    {"trailing-spaces", "spaces at the end of a line", reporter.Info,
+        func(tr *testRun, x Text) int { return tr.trailingSpaces(x.Wb) }},
+    {"characters", "infrequent and unusual characters", reporter.Info,
+        func(tr *testRun, x Text) int { return tr.letterChecks(x.Wb) }},
+    {"case", "internal capitals and inconsistent capitalization", reporter.Info,
+        func(tr *testRun, x Text) int { return tr.caseChecks(x.Pb, x.Pl, x.Wb) }},
+    {"repeated-words", "immediately repeated words, i.e. the the", reporter.Warning,
+        func(tr *testRun, x Text) int { return tr.repeatedWords(x.Pb, x.Pl, x.Wb) }},
+    {"quotes", "unbalanced curly quotes in a paragraph", reporter.Warning,
+        func(tr *testRun, x Text) int { return tr.quoteChecks(x.Pb, x.Pl, x.Wb) }},
+    {"spacing", "blank lines before chapters, sections and paragraphs", reporter.Info,
+        func(tr *testRun, x Text) int { return tr.spacingCheck(x.Wb) }},
+    {"long-lines", "lines longer than -long", reporter.Info,
+        func(tr *testRun, x Text) int { return tr.longLines(x.Wb, x.Long) }},
+    {"short-lines", "lines within a paragraph shorter than -short", reporter.Info,
+        func(tr *testRun, x Text) int { return tr.shortLines(x.Pl, x.Wb, x.Short) }},
+    {"dashes", "dashes written more than one way", reporter.Info,
+        func(tr *testRun, x Text) int { return tr.dashChecks(x.Wb) }},
+    {"ellipses", "ellipses written more than one way or wrongly spaced", reporter.Info,
+        func(tr *testRun, x Text) int { return tr.ellipsisChecks(x.Wb) }},
+    {"punctuation", "spacing around punctuation, brackets and quotes", reporter.Error,
+        func(tr *testRun, x Text) int { return tr.punctuationSpacing(x.Wb) }},
+    {"markup", "markup left from proofing, less -allowmarkup classes", reporter.Error,
+        func(tr *testRun, x Text) int { return tr.markupChecks(x.Wb, x.AllowMarkup) }},
+    {"paragraph-endings", "paragraphs not ending in punctuation", reporter.Warning,
+        func(tr *testRun, x Text) int { return tr.paragraphEndings(x.Pb, x.Pl, x.Wb, x.Short) }},
+    {"paragraph-starts", "paragraphs starting in lower case", reporter.Info,
+        func(tr *testRun, x Text) int { return tr.paragraphStarts(x.Pb, x.Pl, x.Wb, x.Short) }},
+    {"page-remnants", "page separators, page numbers and running headers", reporter.Error,
+        func(tr *testRun, x Text) int { return tr.pageRemnants(x.Wb) }},
+    {"special", "straight and curly quotes both used", reporter.Warning,
+        func(tr *testRun, x Text) int { return tr.specialSituations(x.Wb) }},
+}
 
-    // append to pptxt.log
-    *runlog = append(*runlog, rs...)
-}
\ No newline at end of file
+// run the test on the text
+func (t Test) Run(x Text) Result {
+    tr := &testRun{mk: make(map[int][]string), lm: make(map[int][]string)}
+    count := t.run(tr, x)
+    for i := range tr.fs {
+        tr.fs[i].Check = t.ID
+        tr.fs[i].Severity = t.Severity
+    }
+    return Result{tr.s[0], count, tr.s[1:], tr.mk, tr.lm, tr.fs}
+}
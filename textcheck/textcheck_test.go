@@ -0,0 +1,319 @@
+package textcheck
+
+import (
+    "pptxt/para"
+    "pptxt/reporter"
+    "reflect"
+    "strings"
+    "sync"
+    "testing"
+)
+
+// a short book with something for most of the tests to find
+const book = `THE BOOK
+
+
+
+CHAPTER I
+
+
+It was a dark night* and  the the wind blew.
+“He said ‘come here’ and left,” she said--then — stopped.
+Wait... what . . . now… and word ...word
+word ,word and end.Next and ( text ) all wrong
+<i>italic</i> and [Illustration: A picture]
+short line
+and this following line is long enough to count as a full line of prose.
+
+xiv
+
+Both 'straight' and ‘curly’ quotes.`
+
+func text() Text {
+    wb := strings.Split(book, "\n")
+    pb, pl := para.Build(wb)
+    return Text{Pb: pb, Pl: pl, Wb: wb, Long: 60, Short: 30}
+}
+
+// the test with this ID
+func testByID(t *testing.T, id string) Test {
+    t.Helper()
+    for _, test := range Tests {
+        if test.ID == id {
+            return test
+        }
+    }
+    t.Fatalf("no test %s", id)
+    return Test{}
+}
+
+// running a test again on the same text gives the same results
+func TestRunRepeatable(t *testing.T) {
+    x := text()
+    for _, test := range Tests {
+        first := test.Run(x)
+        second := test.Run(x)
+        if first.Count != second.Count {
+            t.Errorf("%s: count %d then %d", test.ID, first.Count, second.Count)
+        }
+        if !reflect.DeepEqual(first.Findings, second.Findings) {
+            t.Errorf("%s: findings differ on the second run", test.ID)
+        }
+    }
+}
+
+// tests run at the same time give the results they give one at a time
+// run with -race
+func TestRunConcurrent(t *testing.T) {
+    x := text()
+    want := make([]Result, len(Tests))
+    for i, test := range Tests {
+        want[i] = test.Run(x)
+    }
+    var wg sync.WaitGroup
+    got := make([][]Result, 4)
+    for g := range got {
+        got[g] = make([]Result, len(Tests))
+        wg.Add(1)
+        go func(g int) {
+            defer wg.Done()
+            for i, test := range Tests {
+                got[g][i] = test.Run(x)
+            }
+        }(g)
+    }
+    wg.Wait()
+    for g := range got {
+        for i, test := range Tests {
+            if got[g][i].Count != want[i].Count || !reflect.DeepEqual(got[g][i].Findings, want[i].Findings) {
+                t.Errorf("%s: goroutine %d results differ", test.ID, g)
+            }
+        }
+    }
+}
+
+// apostrophes are not taken for quotes, nor quotes for apostrophes
+func TestQuoteBalance(t *testing.T) {
+    tests := []struct {
+        p, next string
+        want    string
+    }{
+        {"“Give ’em what they want,” he said.", "", ""},
+        {"‘Give ’em what they want,’ he said.", "", ""},
+        {"“Rock ’n’ roll,” she said.", "", ""},
+        {"“I saw the boys’ hats,” he said.", "", ""},
+        {"‘I saw the boys’ hats,’ he said.", "", ""},
+        {"“He said ‘the boys’ hats,’ and left.”", "", ""},
+        {"‘Go,’ he said. ‘The boys’ hats.’", "", ""},
+        {"It was the boys’ fault.", "", ""},
+        {"“Come here,” she said, “and stay.", "Quietly.", "unclosed “"},
+        {"“The first paragraph of a long speech.", "“And the second.”", ""},
+        {"‘The first paragraph of a long speech.", "‘And the second.’", ""},
+        {"He said,” and left.", "", "” without opening “"},
+        {"“He said, “come.”", "", "“ inside open “"},
+        {"“He said ‘come.”", "", "” closes open ‘"},
+        {"He said, ‘come,’ and ’ left.’", "", "’ without opening ‘"},
+    }
+    for _, tt := range tests {
+        if got := quoteBalance(tt.p, tt.next); got != tt.want {
+            t.Errorf("quoteBalance(%q, %q) = %q, want %q", tt.p, tt.next, got, tt.want)
+        }
+    }
+}
+
+// each run of dashes in a line is classed by its style
+func TestDashStyle(t *testing.T) {
+    tests := []struct {
+        line string
+        run  string  // the first run of dashes in line
+        want string
+    }{
+        {"well-known", "-", "hyphen"},
+        {"word - word", "-", "spaced hyphen"},
+        {"word--word", "--", "double hyphen"},
+        {"-----File: 001.png---", "-----", "hyphen rule"},
+        {"word---word", "---", "hyphen rule"},
+        {"1890–1900", "–", "en-dash"},
+        {"word—word", "—", "em-dash"},
+        {"word — word", "—", "spaced em-dash"},
+        {"word——", "——", "long em-dash"},
+        {"—word", "—", "em-dash at line start"},
+        {"and then—", "—", "em-dash at line end"},
+        {"word-—word", "-—", "mixed dashes"},
+    }
+    for _, tt := range tests {
+        start := strings.Index(tt.line, tt.run)
+        if got := dashStyle(tt.line, start, start+len(tt.run)); got != tt.want {
+            t.Errorf("dashStyle(%q) = %q, want %q", tt.line, got, tt.want)
+        }
+    }
+}
+
+// separators and rules of hyphens do not make double hyphens the
+// usual em-dash
+func TestDashChecksRules(t *testing.T) {
+    wb := []string{
+        "-----File: 001.png---",
+        "He paused--and then spoke.",
+        "-----File: 002.png---",
+        "She waited—and left.",
+        "Then he—stopped.",
+    }
+    r := testByID(t, "dashes").Run(Text{Wb: wb})
+    if len(r.Findings) != 1 || r.Findings[0].Line != 2 || r.Findings[0].Message != "double hyphen" {
+        t.Errorf("findings %v, want the double hyphen on line 2", r.Findings)
+    }
+}
+
+// only numbers and well-formed roman numerals are page numbers
+func TestPageNumber(t *testing.T) {
+    tests := []struct {
+        line string
+        want bool
+    }{
+        {"12", true},
+        {"  (12)  ", true},
+        {"xiv", true},
+        {"[ix]", true},
+        {"mcmxii", true},
+        {"did", false},
+        {"mild", false},
+        {"civil", false},
+        {"mix", false},
+        {"dim", false},
+        {"iiii", false},
+        {"12 men", false},
+        {"", false},
+    }
+    for _, tt := range tests {
+        if got := pageNumber(tt.line); got != tt.want {
+            t.Errorf("pageNumber(%q) = %v, want %v", tt.line, got, tt.want)
+        }
+    }
+}
+
+// a short line followed by a full one is broken wrapping, not poetry
+func TestShortLines(t *testing.T) {
+    wb := []string{
+        "short line",
+        "and this following line is long enough to count as a full line of prose.",
+    }
+    pb, pl := para.Build(wb)
+    r := testByID(t, "short-lines").Run(Text{Pb: pb, Pl: pl, Wb: wb, Long: 72, Short: 55})
+    if len(r.Findings) != 1 || r.Findings[0].Line != 1 {
+        t.Errorf("findings %v, want line 1", r.Findings)
+    }
+}
+
+// an em-dash ending a line is reported with its column
+func TestDashAtLineEnd(t *testing.T) {
+    wb := []string{"She began to say—", "and stopped."}
+    r := testByID(t, "dashes").Run(Text{Wb: wb})
+    if len(r.Findings) != 1 || r.Findings[0].Line != 1 || r.Findings[0].Column != 17 ||
+        r.Findings[0].Message != "em-dash at line end" {
+        t.Errorf("findings %v, want em-dash at line end on line 1, column 17", r.Findings)
+    }
+}
+
+// headings are not reported, the paragraph after one is
+func TestParagraphEndings(t *testing.T) {
+    tests := []struct {
+        name  string
+        wb    []string
+        lines []int  // reported
+    }{
+        {"heading and first paragraph", []string{"", "", "CHAPTER I", "", "",
+            "It was a dark night and the wind blew through the trees of the",
+            "wood without a stop", "", "The end."}, []int{7}},
+        {"title case heading", []string{"", "", "The Storm", "", "The wind blew."}, nil},
+        {"heading in capitals after one blank", []string{"The wind blew.", "", "THE STORM", "", "It blew."}, nil},
+        {"short line after one blank", []string{"The wind blew.", "", "and on"}, []int{3}},
+    }
+    for _, tt := range tests {
+        pb, pl := para.Build(tt.wb)
+        r := testByID(t, "paragraph-endings").Run(Text{Pb: pb, Pl: pl, Wb: tt.wb, Short: 55})
+        var lines []int
+        for _, f := range r.Findings {
+            lines = append(lines, f.Line)
+        }
+        if !reflect.DeepEqual(lines, tt.lines) {
+            t.Errorf("%s: lines %v, want %v", tt.name, lines, tt.lines)
+        }
+    }
+}
+
+// tests find what they should in the book
+func TestFindings(t *testing.T) {
+    x := text()
+    tests := []struct {
+        id   string
+        want []reporter.Finding  // less check and severity
+    }{
+        {"asterisk", []reporter.Finding{
+            {Line: 8, Column: 20, Word: "*", Message: "asterisk"}}},
+        {"repeated-words", []reporter.Finding{
+            {Line: 8, Column: 27, Word: "the the", Message: "repeated word"},
+            {Line: 10, Column: 29, Word: "word", Message: "repeated word, across a line break"}}},
+        {"punctuation", []reporter.Finding{
+            {Line: 11, Column: 5, Word: " ,", Message: "space before punctuation"},
+            {Line: 11, Column: 19, Word: "end.Next", Message: "missing space after punctuation"},
+            {Line: 11, Column: 30, Word: "( ", Message: "space after opening bracket"},
+            {Line: 11, Column: 35, Word: " )", Message: "space before closing bracket"}}},
+        {"markup", []reporter.Finding{
+            {Line: 12, Column: 1, Word: "<i>", Message: "HTML tag"},
+            {Line: 12, Column: 10, Word: "</i>", Message: "HTML tag"},
+            {Line: 12, Column: 19, Word: "[Illustration", Message: "unconverted illustration"}}},
+    }
+    for _, tt := range tests {
+        test := testByID(t, tt.id)
+        r := test.Run(x)
+        for i := range tt.want {
+            tt.want[i].Check = test.ID
+            tt.want[i].Severity = test.Severity
+        }
+        if !reflect.DeepEqual(r.Findings, tt.want) {
+            t.Errorf("%s: findings %v, want %v", tt.id, r.Findings, tt.want)
+        }
+    }
+}
+
+// each caret of the punctuation check is under the column it reports
+func TestPunctuationCarets(t *testing.T) {
+    r := testByID(t, "punctuation").Run(text())
+    var carets []int
+    for i, line := range r.Lines {
+        if strings.TrimSpace(line) != "^" || i == 0 {
+            continue
+        }
+        quoted := r.Lines[i-1]
+        start := strings.Index(quoted, ": ") + 2  // where the line of text starts
+        carets = append(carets, strings.Index(line, "^")-start+1)
+    }
+    var cols []int
+    for _, f := range r.Findings {
+        cols = append(cols, f.Column)
+    }
+    if len(carets) == 0 || !reflect.DeepEqual(carets, cols) {
+        t.Errorf("carets at %v, findings at %v", carets, cols)
+    }
+}
+
+// markup is reported by class, less the classes allowed
+func TestMarkupClasses(t *testing.T) {
+    x := text()
+    r := testByID(t, "markup").Run(x)
+    for _, class := range []string{"HTML tag (-allowmarkup html)", "unconverted illustration (-allowmarkup illustration)"} {
+        found := false
+        for _, line := range r.Lines {
+            found = found || strings.TrimSpace(line) == class
+        }
+        if !found {
+            t.Errorf("no %q in the report", class)
+        }
+    }
+    x.AllowMarkup = []string{"html"}
+    r = testByID(t, "markup").Run(x)
+    if len(r.Findings) != 1 || r.Findings[0].Message != "unconverted illustration" {
+        t.Errorf("with html allowed, findings %v", r.Findings)
+    }
+}
@@ -0,0 +1,125 @@
+package variant
+
+import (
+    "fmt"
+    "pptxt/fileio"
+    "pptxt/reporter"
+    "pptxt/wfreq"
+    "strings"
+    "time"
+)
+
+// variant check reports when more than one spelling from a set of
+// variants, i.e. color and colour, is used in the text
+// vd holds the variant sets, case is ignored
+// returns a finding for each use of a less common variant in a set,
+// suggesting the most common
+func Variantcheck(wb []string, vd [][]string, runlog *[]string, rpt *reporter.Report, fname string) []reporter.Finding {
+    var s []string
+    var rs []string
+    rs = append(rs, "spelling variant check")
+
+    s = append(s, fmt.Sprintf("spelling variant check report \nstarted: %s\n------------------------------",
+        time.Now().Format(time.RFC850)))
+
+    if len(vd) == 0 {
+        s = append(s, "  no spelling variants in data file. check skipped.")
+        rs = append(rs, "  no spelling variant data")
+        if fname != "" {
+            fileio.SaveText(s, fname, true, true)
+        }
+        rpt.Add("spelling variant check", 0, s[1:], nil)
+        *runlog = append(*runlog, rs...)
+        return nil
+    }
+
+    wlm, wb2 := wfreq.GetWordList(wb) // wordlist with word, frequency of word in map, wb2
+    lwm := make(map[string]int)  // the same, case insensitive
+    for word, count := range wlm {
+        lwm[strings.ToLower(word)] += count
+    }
+
+    count := 0
+    marks := make(map[int][]string)  // variants on each line shown
+    var fs []reporter.Finding
+    for _, set := range vd {
+        var used []string  // members of this set found in the text
+        for _, word := range set {
+            if lwm[strings.ToLower(word)] > 0 {
+                used = append(used, strings.ToLower(word))
+            }
+        }
+        if len(used) < 2 {
+            continue  // used consistently
+        }
+        count++
+        var t []string
+        for _, word := range used {
+            t = append(t, fmt.Sprintf("%s (%d)", word, lwm[word]))
+        }
+        s = append(s, strings.Join(t, " / "))
+        major := used[0]  // the most common
+        for _, word := range used {
+            if lwm[word] > lwm[major] {
+                major = word
+            }
+        }
+        for n, line := range wb {
+            for w := range wb2[n] {
+                if lw := strings.ToLower(w); lw != major && contains(used, lw) {
+                    ww, col := reporter.Locate(line, w)  // as written, i.e. with ’
+                    fs = append(fs, reporter.Finding{Check: "variants", Severity: reporter.Info,
+                        Line: n + 1, Column: col, Word: ww,
+                        Message: fmt.Sprintf("spelling variant, also %s (%d)", major, lwm[major]),
+                        Suggestion: major})
+                }
+            }
+        }
+        // show the first occurrence of each
+        for _, word := range used {
+            for n, line := range wb {
+                found := false
+                for w := range wb2[n] {
+                    if strings.ToLower(w) == word {
+                        found = true
+                        marks[n] = append(marks[n], w)
+                        break
+                    }
+                }
+                if found {
+                    s = append(s, fmt.Sprintf("  %s", word))
+                    s = append(s, fmt.Sprintf("  %6d: %s", n+1, line))
+                    break
+                }
+            }
+        }
+        s = append(s, "")
+    }
+    if count == 0 {
+        s = append(s, "  no mixed spelling variants found in text.")
+    }
+
+    rs = append(rs, fmt.Sprintf("  mixed spelling variants: %d sets", count))
+
+    // generate logvariant.txt from s, unless per-check logs are off
+    if fname != "" {
+        fileio.SaveText(s, fname, true, true)
+    }
+
+    // add to the report, without the log header
+    rpt.Add("spelling variant check", count, s[1:], marks)
+
+    // append to pptxt.log
+    *runlog = append(*runlog, rs...)
+    return fs
+}
+
+// true if words holds word
+func contains(words []string, word string) bool {
+    for _, w := range words {
+        if w == word {
+            return true
+        }
+    }
+    return false
+}
@@ -6,6 +6,36 @@ import (
     "unicode"
 )
 
+// hyphenated words "auburn-haired" become "auburn①haired"
+// to preserve that it is one (hyphenated) word.
+// same for single quotes within words, which are always
+// straightened within words
+var re1 = regexp.MustCompile(`(\w)\-(\w)`)
+var re2 = regexp.MustCompile(`(\w)['’](\w)`)
+
+// the words on one line
+func words(element string) []string {
+    f := func(c rune) bool {
+        return !unicode.IsLetter(c) && !unicode.IsNumber(c)
+    }
+    // need to preprocess each line
+    // retain [-'’] between letters
+    // need this twice to handle alternates i.e. r-u-d-e
+    element = re1.ReplaceAllString(element, `${1}①${2}`)
+    element = re1.ReplaceAllString(element, `${1}①${2}`)
+    // need this twice to handle alternates i.e. fo’c’s’le
+    element = re2.ReplaceAllString(element, `${1}②${2}`)
+    element = re2.ReplaceAllString(element, `${1}②${2}`)
+    // all words with special characters are protected
+    t := (strings.FieldsFunc(element, f))
+    for n, word := range t {
+        // put the special characters back in there
+        s := strings.Replace(word, "①", "-", -1)
+        t[n] = strings.Replace(s, "②", "'", -1)
+    }
+    return t
+}
+
 /*  input: a slice of strings that is the book
     output:
         1. a map of words and frequency of occurence of each word
@@ -14,35 +44,13 @@ import (
     */
 
 func GetWordList(wb []string) (map[string]int, []map[string]struct{}) {
-    f := func(c rune) bool {
-        return !unicode.IsLetter(c) && !unicode.IsNumber(c)
-    }
     m := make(map[string]int)  // map to hold words, counts
     var m2 []map[string]struct{}
 
-    // hyphenated words "auburn-haired" become "auburn①haired"
-    // to preserve that it is one (hyphenated) word.
-    // same for single quotes within words, which are always
-    // straightened within words
-    var re1 = regexp.MustCompile(`(\w)\-(\w)`)
-    var re2 = regexp.MustCompile(`(\w)['’](\w)`)
     for _, element := range wb {
         rtn := make(map[string]struct{},0)  // a set; empty structs take no memory
-        // need to preprocess each line
-        // retain [-'’] between letters
-        // need this twice to handle alternates i.e. r-u-d-e
-        element := re1.ReplaceAllString(element, `${1}①${2}`)
-        element = re1.ReplaceAllString(element, `${1}①${2}`)
-        // need this twice to handle alternates i.e. fo’c’s’le
-        element = re2.ReplaceAllString(element, `${1}②${2}`)
-        element = re2.ReplaceAllString(element, `${1}②${2}`)
-        // all words with special characters are protected
-        t := (strings.FieldsFunc(element, f))
-        for _, word := range t {
-            // put the special characters back in there
-            s := strings.Replace(word, "①", "-", -1)
-            s = strings.Replace(s, "②", "'", -1)
-            // and build the map
+        for _, s := range words(element) {
+            // build the map
             if _, ok := m[s]; ok {  // if it is there already, increment
                m[s] = m[s] + 1
             } else {
@@ -54,3 +62,56 @@ func GetWordList(wb []string) (map[string]int, []map[string]struct{}) {
     }
     return m, m2
 }
+
+// language span markup, <lang fr>...</lang>, which may cross lines and nest
+var relang = regexp.MustCompile(`<lang\s+([A-Za-z-]+)\s*>|</lang>`)
+
+/*  as GetWordList, but tracks the language span each word appears in
+    input: a slice of strings that is the book
+    output:
+        1. a map of words and frequency of occurence of each word
+        2. a slice with each element 1:1 with the lines of the text
+           containing a set, per line, of words on that line
+        3. for each language, a map of the words in its spans and their
+           frequency. words outside any span are under language ""
+    the markup itself is not counted as words
+    */
+
+func GetLangWordList(wb []string) (map[string]int, []map[string]struct{}, map[string]map[string]int) {
+    m := make(map[string]int)  // map to hold words, counts
+    var m2 []map[string]struct{}
+    m3 := make(map[string]map[string]int)
+    var spans []string  // stack of open language spans
+
+    count := func(text string, rtn map[string]struct{}) {
+        lang := ""
+        if len(spans) > 0 {
+            lang = spans[len(spans)-1]
+        }
+        if m3[lang] == nil {
+            m3[lang] = make(map[string]int)
+        }
+        for _, s := range words(text) {
+            m[s] += 1
+            m3[lang][s] += 1
+            rtn[s] = struct{}{}
+        }
+    }
+
+    for _, element := range wb {
+        rtn := make(map[string]struct{},0)  // a set; empty structs take no memory
+        last := 0
+        for _, loc := range relang.FindAllStringSubmatchIndex(element, -1) {
+            count(element[last:loc[0]], rtn)  // text before the tag
+            if loc[2] >= 0 {
+                spans = append(spans, strings.ToLower(element[loc[2]:loc[3]]))  // <lang xx>
+            } else if len(spans) > 0 {
+                spans = spans[:len(spans)-1]  // </lang>
+            }
+            last = loc[1]
+        }
+        count(element[last:], rtn)
+        m2 = append(m2, rtn)
+    }
+    return m, m2, m3
+}